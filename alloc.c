@@ -0,0 +1,35 @@
+//go:build cgo
+
+#include "cmark_go.h"
+#include "_cgo_export.h"
+
+// cmark_mem callbacks take no context, so each allocator slot has its
+// own set of functions which pass the slot number to Go
+
+#define GO_CMARK_MEM_SLOT(n)                                               \
+  static void *go_cmark_calloc_##n(size_t nmemb, size_t size) {         \
+    return goCmarkAlloc(n, nmemb * size);                                \
+  }                                                                      \
+  static void *go_cmark_realloc_##n(void *ptr, size_t size) {           \
+    return goCmarkRealloc(n, ptr, size);                                 \
+  }                                                                      \
+  static void go_cmark_free_##n(void *ptr) { goCmarkFree(n, ptr); }
+
+GO_CMARK_MEM_SLOT(0)
+GO_CMARK_MEM_SLOT(1)
+GO_CMARK_MEM_SLOT(2)
+GO_CMARK_MEM_SLOT(3)
+GO_CMARK_MEM_SLOT(4)
+GO_CMARK_MEM_SLOT(5)
+GO_CMARK_MEM_SLOT(6)
+GO_CMARK_MEM_SLOT(7)
+
+#define GO_CMARK_MEM(n)                                                    \
+  { go_cmark_calloc_##n, go_cmark_realloc_##n, go_cmark_free_##n }
+
+static cmark_mem go_cmark_mems[] = {
+    GO_CMARK_MEM(0), GO_CMARK_MEM(1), GO_CMARK_MEM(2), GO_CMARK_MEM(3),
+    GO_CMARK_MEM(4), GO_CMARK_MEM(5), GO_CMARK_MEM(6), GO_CMARK_MEM(7),
+};
+
+cmark_mem *go_cmark_mem(int slot) { return &go_cmark_mems[slot]; }
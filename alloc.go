@@ -0,0 +1,272 @@
+//go:build cgo
+
+package cmark
+
+// #include <stdlib.h>
+// #include <string.h>
+// #include "cmark_go.h"
+//
+// cmark_mem *go_cmark_mem(int slot);
+import "C"
+import (
+	"errors"
+	"reflect"
+	"sync"
+	"unsafe"
+)
+
+// Allocator provides the memory cmark uses for a parser and the nodes
+// it creates
+//
+// cmark keeps pointers into this memory, so it must not be Go memory
+// (allocate it with C.malloc or similar). Alloc must return zeroed
+// memory. cmark aborts the program if Alloc or Realloc return nil.
+// Allocators must be comparable, e.g. a pointer type, others are
+// rejected with an error.
+type Allocator interface {
+	Alloc(size int) unsafe.Pointer
+	Realloc(ptr unsafe.Pointer, size int) unsafe.Pointer
+	Free(ptr unsafe.Pointer)
+}
+
+// memSlots is the number of allocators which can be in use at once,
+// it must match the number of slots in alloc.c
+const memSlots = 8
+
+var (
+	memMu         sync.RWMutex
+	memAllocators [memSlots]Allocator
+)
+
+// memSlot returns the slot for an allocator, assigning a free slot
+// if it does not have one yet
+func memSlot(a Allocator) (int, error) {
+	if a == nil {
+		return 0, errors.New("Allocator is nil")
+	}
+	if t := reflect.TypeOf(a); !t.Comparable() {
+		return 0, errors.New("Allocator type " + t.String() + " is not comparable")
+	}
+	memMu.Lock()
+	defer memMu.Unlock()
+	free := -1
+	for i, s := range memAllocators {
+		if s == a {
+			return i, nil
+		}
+		if s == nil && free < 0 {
+			free = i
+		}
+	}
+	if free < 0 {
+		return 0, errors.New("Too many allocators in use")
+	}
+	memAllocators[free] = a
+	return free, nil
+}
+
+// ReleaseAllocator frees the slot of an allocator passed to NewMem or
+// NewParserWithAllocator for reuse by another allocator
+// Call it once every parser and node using the allocator has been
+// closed, ArenaAllocator.Close and Mem.Close call it for you
+func ReleaseAllocator(a Allocator) {
+	if a == nil || !reflect.TypeOf(a).Comparable() {
+		return
+	}
+	releaseMemSlot(a)
+}
+
+// releaseMemSlot frees the slot of an allocator for reuse
+func releaseMemSlot(a Allocator) {
+	memMu.Lock()
+	defer memMu.Unlock()
+	for i, s := range memAllocators {
+		if s == a {
+			memAllocators[i] = nil
+		}
+	}
+}
+
+func slotAllocator(slot C.int) Allocator {
+	memMu.RLock()
+	defer memMu.RUnlock()
+	return memAllocators[slot]
+}
+
+//export goCmarkAlloc
+func goCmarkAlloc(slot C.int, size C.size_t) unsafe.Pointer {
+	return slotAllocator(slot).Alloc(int(size))
+}
+
+//export goCmarkRealloc
+func goCmarkRealloc(slot C.int, ptr unsafe.Pointer, size C.size_t) unsafe.Pointer {
+	return slotAllocator(slot).Realloc(ptr, int(size))
+}
+
+//export goCmarkFree
+func goCmarkFree(slot C.int, ptr unsafe.Pointer) {
+	slotAllocator(slot).Free(ptr)
+}
+
+// freeRendered frees text rendered from node, which cmark allocates
+// with the node's allocator rather than with malloc
+func freeRendered(node *C.cmark_node, text *C.char) {
+	C.go_cmark_mem_free(C.cmark_node_mem(node), unsafe.Pointer(text))
+}
+
+// Mem is a cmark memory allocator
+type Mem struct {
+	mem *C.cmark_mem
+	// alloc is the allocator of a Mem from NewMem, which holds a slot
+	alloc Allocator
+}
+
+// DefaultMem returns cmark's default allocator, which uses malloc
+func DefaultMem() *Mem {
+	return &Mem{mem: C.cmark_get_default_mem_allocator()}
+}
+
+// NewMem returns a cmark allocator which calls alloc
+// Only a few allocators may be in use at once, an error is returned
+// if there are too many
+// Call Close once every parser and node using it has been closed
+func NewMem(alloc Allocator) (*Mem, error) {
+	slot, err := memSlot(alloc)
+	if err != nil {
+		return nil, err
+	}
+	return &Mem{mem: C.go_cmark_mem(C.int(slot)), alloc: alloc}, nil
+}
+
+// Close frees the allocator slot of a Mem from NewMem, every parser
+// and node using it must have been closed
+// Closing DefaultMem does nothing
+func (m *Mem) Close() error {
+	if m.alloc != nil {
+		releaseMemSlot(m.alloc)
+		m.alloc = nil
+	}
+	return nil
+}
+
+// NewCalloc returns an allocator which allocates with calloc
+// This is DefaultMem, cmark's default allocator already uses calloc,
+// to call back into Go for every allocation use NewMem
+func NewCalloc() *Mem {
+	return DefaultMem()
+}
+
+// NewParserWithMem builds a parser whose memory, and the memory of the
+// nodes it creates, comes from mem
+func NewParserWithMem(options Opt, mem *Mem) Parser {
+	return Parser{parser: newParserWithMem(options, mem.mem)}
+}
+
+// NewParserWithAllocator builds a parser whose memory, and the memory
+// of the nodes it creates, comes from alloc
+// Only a few allocators may be in use at once, an error is returned
+// if there are too many
+// alloc keeps its slot until ReleaseAllocator is called, or for an
+// ArenaAllocator until it is closed
+func NewParserWithAllocator(opts Opt, alloc Allocator) (Parser, error) {
+	mem, err := NewMem(alloc)
+	if err != nil {
+		return Parser{}, err
+	}
+	return NewParserWithMem(opts, mem), nil
+}
+
+// arenaAlign is the alignment of arena allocations, each allocation
+// is preceded by a header of this size holding its size
+const arenaAlign = 16
+
+// ArenaAllocator hands out memory from a single block, freeing memory
+// is a no-op and the whole block is released by Close
+// Allocations which do not fit in the block fall back to malloc
+type ArenaAllocator struct {
+	mu    sync.Mutex
+	block unsafe.Pointer
+	size  uintptr
+	off   uintptr
+}
+
+// NewArenaAllocator returns an allocator backed by a block of
+// arenaSize bytes
+// Call Close once every parser and node using it has been closed,
+// which also frees its allocator slot
+func NewArenaAllocator(arenaSize int) *ArenaAllocator {
+	return &ArenaAllocator{
+		block: C.calloc(1, C.size_t(arenaSize)),
+		size:  uintptr(arenaSize),
+	}
+}
+
+func (a *ArenaAllocator) inArena(ptr unsafe.Pointer) bool {
+	p := uintptr(ptr)
+	return a.block != nil && p >= uintptr(a.block) && p < uintptr(a.block)+a.size
+}
+
+// Alloc returns zeroed memory from the arena
+func (a *ArenaAllocator) Alloc(size int) unsafe.Pointer {
+	need := (uintptr(size) + 2*arenaAlign - 1) &^ (arenaAlign - 1)
+	a.mu.Lock()
+	var p unsafe.Pointer
+	if a.block != nil && a.off+need <= a.size {
+		p = unsafe.Add(a.block, a.off)
+		a.off += need
+	}
+	a.mu.Unlock()
+	if p == nil {
+		p = C.calloc(1, C.size_t(need))
+		if p == nil {
+			return nil
+		}
+	}
+	*(*uintptr)(p) = uintptr(size)
+	return unsafe.Add(p, arenaAlign)
+}
+
+// Realloc moves an allocation to a new one of the given size
+func (a *ArenaAllocator) Realloc(ptr unsafe.Pointer, size int) unsafe.Pointer {
+	if ptr == nil {
+		return a.Alloc(size)
+	}
+	old := *(*uintptr)(unsafe.Add(ptr, -arenaAlign))
+	p := a.Alloc(size)
+	if p == nil {
+		return nil
+	}
+	if n := uintptr(size); n < old {
+		old = n
+	}
+	C.memcpy(p, ptr, C.size_t(old))
+	a.Free(ptr)
+	return p
+}
+
+// Free releases allocations which did not fit in the arena,
+// arena memory is only released by Close
+func (a *ArenaAllocator) Free(ptr unsafe.Pointer) {
+	if ptr == nil {
+		return
+	}
+	a.mu.Lock()
+	inArena := a.inArena(ptr)
+	a.mu.Unlock()
+	if !inArena {
+		C.free(unsafe.Add(ptr, -arenaAlign))
+	}
+}
+
+// Close releases the arena and its allocator slot, all parsers and
+// nodes using it must have been closed
+func (a *ArenaAllocator) Close() error {
+	releaseMemSlot(a)
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	C.free(a.block)
+	a.block = nil
+	a.size = 0
+	a.off = 0
+	return nil
+}
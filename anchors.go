@@ -0,0 +1,175 @@
+package cmark
+
+import (
+	"errors"
+	"html"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// slugify lowercases text and replaces each run of characters other
+// than letters and digits with a hyphen
+func slugify(text string) string {
+	var b strings.Builder
+	hyphen := false
+	for _, r := range strings.ToLower(text) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if hyphen && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			b.WriteRune(r)
+			hyphen = false
+		} else {
+			hyphen = true
+		}
+	}
+	return b.String()
+}
+
+// headingIDs returns the headings under root in document order and an
+// id for each made by slug, repeated ids get a "-1", "-2", etc. suffix
+func headingIDs(root Node, slug func(string) string) ([]Node, map[Node]string) {
+	headings := collectNodes(root, NodeHeading)
+	ids := make(map[Node]string, len(headings))
+	used := map[string]int{}
+	for _, h := range headings {
+		id := slug(h.TextContent())
+		base := id
+		for used[id] > 0 {
+			id = base + "-" + strconv.Itoa(used[base])
+			used[base]++
+		}
+		used[id]++
+		ids[h] = id
+	}
+	return headings, ids
+}
+
+// GenerateHeadingIDs returns a unique URL anchor for every heading
+// under root, made from the heading's text lowercased with other
+// characters than letters and digits replaced by hyphens
+// Repeated anchors get a "-1", "-2", etc. suffix
+func GenerateHeadingIDs(root Node) map[Node]string {
+	_, ids := headingIDs(root, slugify)
+	return ids
+}
+
+var headingTag = regexp.MustCompile(`<h[1-6]`)
+
+// injectHeadingIDs adds an id attribute to each heading tag in html,
+// ids are in the order the headings are rendered
+func injectHeadingIDs(html string, ids []string) string {
+	i := 0
+	return headingTag.ReplaceAllStringFunc(html, func(tag string) string {
+		if i >= len(ids) {
+			return tag
+		}
+		i++
+		return tag + ` id="` + ids[i-1] + `"`
+	})
+}
+
+// GenerateHeadingIDsHTML renders html from the document with the
+// anchors from GenerateHeadingIDs as the id of each heading
+//
+// Raw html headings in the document are not distinguished from
+// rendered ones, if the document may contain them do not enable raw
+// html: pass OptSafe to cmark before 0.29, and leave out the unsafe
+// option of later versions
+func GenerateHeadingIDsHTML(root Node, opts Opt) string {
+	return root.RenderHTMLWithAnchors(opts, nil)
+}
+
+// RenderHTMLWithAnchors renders html from the document with an id on
+// every heading made from its text by slugger, repeated ids get a
+// "-1", "-2", etc. suffix
+// A nil slugger lowercases the text and replaces other characters than
+// letters and digits with hyphens, as GenerateHeadingIDs does
+//
+// Raw html headings in the document are not distinguished from
+// rendered ones, if the document may contain them do not enable raw
+// html: pass OptSafe to cmark before 0.29, and leave out the unsafe
+// option of later versions
+func (n Node) RenderHTMLWithAnchors(options Opt, slugger func(text string) string) string {
+	if slugger == nil {
+		slugger = slugify
+	}
+	headings, ids := headingIDs(n, slugger)
+	order := make([]string, len(headings))
+	for i, h := range headings {
+		order[i] = html.EscapeString(ids[h])
+	}
+	return injectHeadingIDs(n.RenderHTML(options), order)
+}
+
+// tocList creates a tight bullet list for TableOfContents
+func tocList() Node {
+	list := NewNode(NodeList)
+	list.SetListType(BulletList)
+	list.SetTightList(true)
+	return list
+}
+
+// tocItem creates a list item linking to #id
+func tocItem(id, text string) Node {
+	item := NewNode(NodeItem)
+	para := NewNode(NodeParagraph)
+	link := NewNode(NodeLink)
+	link.SetURL("#" + id)
+	t := NewNode(NodeText)
+	t.SetLiteral(text)
+	link.AppendChild(t)
+	para.AppendChild(link)
+	item.AppendChild(para)
+	return item
+}
+
+// TableOfContents builds a nested bullet list of links to the headings
+// under root with levels up to maxDepth, linking to the anchors from
+// GenerateHeadingIDs
+//
+// The list is not linked into any tree, call Close if it is not inserted
+func TableOfContents(root Node, maxDepth int) (Node, error) {
+	if maxDepth < 1 {
+		return Node{}, errors.New("maxDepth must be at least 1")
+	}
+	headings, ids := headingIDs(root, slugify)
+	var levels []int
+	var included []Node
+	minLevel := maxDepth
+	for _, h := range headings {
+		if level, _ := h.HeadingLevel(); level <= maxDepth {
+			included = append(included, h)
+			levels = append(levels, level)
+			if level < minLevel {
+				minLevel = level
+			}
+		}
+	}
+
+	toc := tocList()
+	type tocLevel struct {
+		level int
+		list  Node
+	}
+	stack := []tocLevel{{minLevel, toc}}
+	for i, h := range included {
+		for stack[len(stack)-1].level > levels[i] {
+			stack = stack[:len(stack)-1]
+		}
+		for top := stack[len(stack)-1]; top.level < levels[i]; top = stack[len(stack)-1] {
+			parent := top.list.LastChild()
+			if parent.node == nil {
+				parent = NewNode(NodeItem)
+				top.list.AppendChild(parent)
+			}
+			sub := tocList()
+			parent.AppendChild(sub)
+			stack = append(stack, tocLevel{top.level + 1, sub})
+		}
+		stack[len(stack)-1].list.AppendChild(tocItem(ids[h], h.TextContent()))
+	}
+	return toc, nil
+}
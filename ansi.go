@@ -0,0 +1,184 @@
+package cmark
+
+import "strings"
+
+// ANSI escape sequences, each style has its own reset so styles nest
+const (
+	ansiBold      = "\x1b[1m"
+	ansiBoldOff   = "\x1b[22m"
+	ansiItalic    = "\x1b[3m"
+	ansiItalicOff = "\x1b[23m"
+	ansiUnder     = "\x1b[4m"
+	ansiUnderOff  = "\x1b[24m"
+	ansiRev       = "\x1b[7m"
+	ansiRevOff    = "\x1b[27m"
+)
+
+// RenderANSI renders the document as text styled with ANSI escape codes
+// for display in a terminal: headings are bold, emphasis is italic,
+// strong emphasis is bold and italic, code is in reverse video and
+// links are underlined and followed by their URL in parentheses
+// wrapWidth is the wrap width (0 indicates no wrapping)
+func (n Node) RenderANSI(options Opt, wrapWidth int) string {
+	r := ansiRenderer{opts: options, width: wrapWidth}
+	Walk(n, func(n Node, ev Event) error {
+		r.event(n, ev)
+		return nil
+	})
+	return r.out.String()
+}
+
+type ansiRenderer struct {
+	blockWriter
+	opts  Opt
+	width int
+	// inline is the styled content of the current paragraph or heading
+	inline strings.Builder
+	// bold, italic and under count the open nodes setting each style,
+	// which is only reset when the last of them ends
+	bold, italic, under int
+}
+
+func (r *ansiRenderer) event(n Node, ev Event) {
+	typ, _ := n.Type()
+	enter := ev == EventEnter
+	switch typ {
+	case NodeParagraph:
+		if enter {
+			r.startBlock()
+		} else {
+			r.flush()
+		}
+	case NodeHeading:
+		if enter {
+			r.startBlock()
+			r.style(&r.bold, true, ansiBold, ansiBoldOff)
+		} else {
+			r.style(&r.bold, false, ansiBold, ansiBoldOff)
+			r.flush()
+		}
+	case NodeBlockQuote:
+		r.quote(enter, "│ ")
+	case NodeList:
+		r.list(n, enter)
+	case NodeItem:
+		r.item(enter, "• ")
+	case NodeCodeBlock:
+		r.startBlock()
+		for _, line := range strings.Split(strings.TrimSuffix(n.Literal(), "\n"), "\n") {
+			r.writeLine(ansiRev + line + ansiRevOff)
+		}
+		r.blank = true
+	case NodeHTMLBlock:
+		r.startBlock()
+		for _, line := range strings.Split(strings.TrimSuffix(n.Literal(), "\n"), "\n") {
+			r.writeLine(line)
+		}
+		r.blank = true
+	case NodeThematicBreak:
+		r.startBlock()
+		width := r.width
+		if width <= 0 {
+			width = 40
+		}
+		r.writeLine(strings.Repeat("─", width))
+		r.blank = true
+	case NodeText, NodeHTMLInline:
+		r.inline.WriteString(n.Literal())
+	case NodeCode:
+		r.inline.WriteString(ansiRev + n.Literal() + ansiRevOff)
+	case NodeSoftBreak:
+		if r.opts&OptHardBreaks != 0 {
+			r.inline.WriteString("\n")
+		} else {
+			r.inline.WriteString(" ")
+		}
+	case NodeLineBreak:
+		r.inline.WriteString("\n")
+	case NodeEmph:
+		r.style(&r.italic, enter, ansiItalic, ansiItalicOff)
+	case NodeStrong:
+		r.style(&r.bold, enter, ansiBold, ansiBoldOff)
+		r.style(&r.italic, enter, ansiItalic, ansiItalicOff)
+	case NodeLink:
+		r.style(&r.under, enter, ansiUnder, ansiUnderOff)
+		if !enter {
+			r.inline.WriteString(" (" + n.URL() + ")")
+		}
+	case NodeImage:
+		if enter {
+			r.inline.WriteString("[image: ")
+		} else {
+			r.inline.WriteString("] (" + n.URL() + ")")
+		}
+	}
+}
+
+// style starts a style when the first node setting it is entered and
+// resets it when the last one exits, so enclosing nodes keep it
+func (r *ansiRenderer) style(count *int, enter bool, on, off string) {
+	if enter {
+		if *count == 0 {
+			r.inline.WriteString(on)
+		}
+		*count++
+		return
+	}
+	*count--
+	if *count == 0 {
+		r.inline.WriteString(off)
+	}
+}
+
+// flush writes the current inline content, wrapped to the width
+func (r *ansiRenderer) flush() {
+	text := r.inline.String()
+	r.inline.Reset()
+	width := r.width - ansiWidth(strings.Join(r.prefixes, ""))
+	for _, line := range strings.Split(text, "\n") {
+		for _, wrapped := range ansiWrap(line, width) {
+			r.writeLine(wrapped)
+		}
+	}
+	r.blank = true
+}
+
+// ansiWidth returns the number of visible runes in s,
+// ignoring escape sequences
+func ansiWidth(s string) int {
+	width := 0
+	escape := false
+	for _, c := range s {
+		switch {
+		case c == '\x1b':
+			escape = true
+		case escape:
+			escape = c != 'm'
+		default:
+			width++
+		}
+	}
+	return width
+}
+
+// ansiWrap splits line into lines of at most width visible runes,
+// breaking at spaces
+// width <= 0 indicates no wrapping
+func ansiWrap(line string, width int) []string {
+	if width <= 0 || ansiWidth(line) <= width {
+		return []string{line}
+	}
+	var lines []string
+	var cur string
+	for _, word := range strings.Split(line, " ") {
+		if cur != "" && ansiWidth(cur)+1+ansiWidth(word) > width {
+			lines = append(lines, cur)
+			cur = word
+		} else if cur != "" {
+			cur += " " + word
+		} else {
+			cur = word
+		}
+	}
+	return append(lines, cur)
+}
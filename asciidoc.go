@@ -0,0 +1,148 @@
+package cmark
+
+import "strings"
+
+// RenderAsciiDoc renders the document as AsciiDoc: headings are
+// prefixed with "=" characters, a level 1 heading becomes a level 1
+// section ("=="), emphasis is _text_, strong emphasis is *text*, code
+// blocks are delimited by "----" and links become link:url[text]
+// Text is not escaped, so AsciiDoc markup in the text is kept
+func (n Node) RenderAsciiDoc(options Opt) string {
+	r := asciiDocRenderer{opts: options}
+	Walk(n, func(n Node, ev Event) error {
+		r.event(n, ev)
+		return nil
+	})
+	return r.out.String()
+}
+
+type asciiDocRenderer struct {
+	opts Opt
+	out  strings.Builder
+	// lists holds whether each enclosing list is ordered
+	lists []bool
+	// attached is set when the next block directly follows a list
+	// marker or delimiter line and needs no separator
+	attached bool
+}
+
+func (r *asciiDocRenderer) event(n Node, ev Event) {
+	typ, _ := n.Type()
+	enter := ev == EventEnter
+	switch typ {
+	case NodeParagraph:
+		if enter {
+			r.startBlock(n)
+		} else {
+			r.out.WriteString("\n")
+		}
+	case NodeHeading:
+		if enter {
+			r.startBlock(n)
+			level, _ := n.HeadingLevel()
+			r.out.WriteString(strings.Repeat("=", level+1) + " ")
+		} else {
+			r.out.WriteString("\n")
+		}
+	case NodeBlockQuote:
+		if enter {
+			r.startBlock(n)
+			r.out.WriteString("____\n")
+			r.attached = true
+		} else {
+			r.out.WriteString("____\n")
+		}
+	case NodeList:
+		if enter {
+			r.startBlock(n)
+			typ, _ := n.ListType()
+			r.lists = append(r.lists, typ == OrderedList)
+		} else {
+			r.lists = r.lists[:len(r.lists)-1]
+		}
+	case NodeItem:
+		if enter {
+			marker := "*"
+			if r.lists[len(r.lists)-1] {
+				marker = "."
+			}
+			r.out.WriteString(strings.Repeat(marker, len(r.lists)) + " ")
+			r.attached = true
+		} else if r.attached {
+			// an empty item
+			r.out.WriteString("\n")
+			r.attached = false
+		}
+	case NodeCodeBlock:
+		r.startBlock(n)
+		if info := strings.Fields(n.FenceInfo()); len(info) > 0 {
+			r.out.WriteString("[source," + info[0] + "]\n")
+		}
+		r.out.WriteString("----\n" + withNewline(n.Literal()) + "----\n")
+	case NodeHTMLBlock:
+		r.startBlock(n)
+		r.out.WriteString("++++\n" + withNewline(n.Literal()) + "++++\n")
+	case NodeThematicBreak:
+		r.startBlock(n)
+		r.out.WriteString("'''\n")
+	case NodeText:
+		r.out.WriteString(n.Literal())
+	case NodeHTMLInline:
+		r.out.WriteString("+++" + n.Literal() + "+++")
+	case NodeCode:
+		r.out.WriteString("`" + n.Literal() + "`")
+	case NodeSoftBreak:
+		switch {
+		case r.opts&OptHardBreaks != 0:
+			r.out.WriteString(" +\n")
+		case r.opts&OptNoBreaks != 0:
+			r.out.WriteString(" ")
+		default:
+			r.out.WriteString("\n")
+		}
+	case NodeLineBreak:
+		r.out.WriteString(" +\n")
+	case NodeEmph:
+		r.out.WriteString("_")
+	case NodeStrong:
+		r.out.WriteString("*")
+	case NodeLink:
+		if enter {
+			r.out.WriteString("link:" + n.URL() + "[")
+		} else {
+			r.out.WriteString("]")
+		}
+	case NodeImage:
+		if enter {
+			r.out.WriteString("image:" + n.URL() + "[")
+		} else {
+			r.out.WriteString("]")
+		}
+	}
+}
+
+// startBlock separates a block from the one before it, blocks after
+// the first in a list item are attached to it with a "+" line
+func (r *asciiDocRenderer) startBlock(n Node) {
+	if r.attached {
+		r.attached = false
+		return
+	}
+	if ptyp, _ := n.Parent().Type(); ptyp == NodeItem {
+		if typ, _ := n.Type(); typ != NodeList {
+			r.out.WriteString("+\n")
+		}
+		return
+	}
+	if r.out.Len() > 0 {
+		r.out.WriteString("\n")
+	}
+}
+
+// withNewline returns s ending in a newline
+func withNewline(s string) string {
+	if s == "" || strings.HasSuffix(s, "\n") {
+		return s
+	}
+	return s + "\n"
+}
@@ -0,0 +1,40 @@
+package cmark
+
+import "fmt"
+
+// ASTNode is a Go copy of a node and its subtree,
+// it remains valid after the tree is closed
+type ASTNode struct {
+	Type     string
+	Literal  string
+	Children []*ASTNode
+	// Attributes holds the node specific properties: "level" for
+	// headings, "list_type", "list_delim", "list_start" and "tight" for
+	// lists, "info" for code blocks, "url" and "title" for links and
+	// images, "on_enter" and "on_exit" for custom nodes
+	Attributes map[string]string
+}
+
+// ToAST copies the subtree of this node into Go types
+func (n Node) ToAST() (*ASTNode, error) {
+	typ, err := n.Type()
+	if err != nil {
+		return nil, err
+	}
+	a := &ASTNode{
+		Type:       typ.String(),
+		Literal:    n.Literal(),
+		Attributes: map[string]string{},
+	}
+	for k, v := range attributesOf(n).values(typ) {
+		a.Attributes[k] = fmt.Sprint(v)
+	}
+	for c := n.FirstChild(); c.node != nil; c = c.Next() {
+		child, err := c.ToAST()
+		if err != nil {
+			return nil, err
+		}
+		a.Children = append(a.Children, child)
+	}
+	return a, nil
+}
@@ -0,0 +1,105 @@
+//go:build cgo
+
+package cmark
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// largeDocument is about 160 KB of markdown using most block and
+// inline constructs, the size of the CommonMark spec
+var largeDocument = func() string {
+	var b strings.Builder
+	for i := 0; b.Len() < 160*1024; i++ {
+		fmt.Fprintf(&b, "## Section %d\n\n", i)
+		b.WriteString("Some *emphasis*, **strong** text, `code`, a [link](https://example.com \"title\")\n")
+		b.WriteString("and an ![image](/img.png) with \"quotes\" -- and <span>inline html</span>.\n\n")
+		b.WriteString("> A block quote\n> over two lines\n\n")
+		b.WriteString("1. First\n2. Second\n   - nested\n   - list\n\n")
+		b.WriteString("```go\nfunc main() {\n\tprintln(\"hi\")\n}\n```\n\n")
+		b.WriteString("<div>\nhtml block\n</div>\n\n---\n\n")
+	}
+	return b.String()
+}()
+
+func parseLarge(b *testing.B) Node {
+	b.Helper()
+	p := NewParser(OptDefault)
+	defer p.Close()
+	return p.ParseString(largeDocument)
+}
+
+func BenchmarkParseLarge(b *testing.B) {
+	b.SetBytes(int64(len(largeDocument)))
+	p := NewParser(OptDefault)
+	defer p.Close()
+	for i := 0; i < b.N; i++ {
+		p.ParseString(largeDocument).Close()
+	}
+}
+
+func BenchmarkRenderHTMLLarge(b *testing.B) {
+	doc := parseLarge(b)
+	defer doc.Close()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		doc.RenderHTML(OptDefault)
+	}
+}
+
+func BenchmarkRenderCommonMarkLarge(b *testing.B) {
+	doc := parseLarge(b)
+	defer doc.Close()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		doc.RenderCommonMark(OptDefault, 0)
+	}
+}
+
+func BenchmarkRenderLaTeXLarge(b *testing.B) {
+	doc := parseLarge(b)
+	defer doc.Close()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		doc.RenderLaTeX(OptDefault, 0)
+	}
+}
+
+func BenchmarkRenderManLarge(b *testing.B) {
+	doc := parseLarge(b)
+	defer doc.Close()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		doc.RenderMan(OptDefault, 0)
+	}
+}
+
+func BenchmarkIterFull(b *testing.B) {
+	doc := parseLarge(b)
+	defer doc.Close()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		iter := doc.Iter()
+		for ev := iter.Next(); ev != EventDone; ev = iter.Next() {
+		}
+		iter.Close()
+	}
+}
+
+func BenchmarkParseBytesStream(b *testing.B) {
+	for size := 1 << 10; size <= 1<<20; size <<= 2 {
+		b.Run(strconv.Itoa(size>>10)+"KB", func(b *testing.B) {
+			b.SetBytes(int64(len(largeDocument)))
+			for i := 0; i < b.N; i++ {
+				doc, err := ParseBytesStream(strings.NewReader(largeDocument), size, OptDefault)
+				if err != nil {
+					b.Fatal(err)
+				}
+				doc.Close()
+			}
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package cmark
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// blockList is a list being written by a blockWriter
+type blockList struct {
+	ordered bool
+	tight   bool
+	next    int
+}
+
+// blockWriter writes the lines of the plain text renderers, RST and
+// ANSI, each prefixed by the enclosing block quotes and list items
+type blockWriter struct {
+	out strings.Builder
+	// prefixes are the line prefixes of the enclosing block quotes and items
+	prefixes []string
+	// marker replaces the innermost prefix on the next line written
+	marker string
+	lists  []blockList
+	// blank is set when the next block should be preceded by a blank line
+	blank bool
+}
+
+// startBlock writes the blank line separating this block from the last
+func (w *blockWriter) startBlock() {
+	if w.blank {
+		w.out.WriteString(strings.TrimRight(strings.Join(w.prefixes, ""), " ") + "\n")
+	}
+	w.blank = false
+}
+
+// writeLine writes a line with the current prefixes, without trailing
+// spaces
+func (w *blockWriter) writeLine(line string) {
+	prefixes := strings.Join(w.prefixes, "")
+	if w.marker != "" && len(w.prefixes) > 0 {
+		prefixes = strings.Join(w.prefixes[:len(w.prefixes)-1], "") + w.marker
+		w.marker = ""
+	}
+	w.out.WriteString(strings.TrimRight(prefixes+line, " ") + "\n")
+}
+
+// quote enters or leaves a block quote whose lines start with prefix
+func (w *blockWriter) quote(enter bool, prefix string) {
+	if enter {
+		w.startBlock()
+		w.prefixes = append(w.prefixes, prefix)
+	} else {
+		w.prefixes = w.prefixes[:len(w.prefixes)-1]
+	}
+}
+
+// list enters or leaves the list n
+func (w *blockWriter) list(n Node, enter bool) {
+	if enter {
+		w.startBlock()
+		typ, _ := n.ListType()
+		start, _ := n.ListStart()
+		w.lists = append(w.lists, blockList{ordered: typ == OrderedList, tight: n.TightList(), next: start})
+	} else {
+		w.lists = w.lists[:len(w.lists)-1]
+		w.blank = true
+	}
+}
+
+// item enters or leaves an item of the innermost list, bullet is the
+// marker of bullet list items
+// The item's lines are indented by the width of its marker
+func (w *blockWriter) item(enter bool, bullet string) {
+	if enter {
+		l := &w.lists[len(w.lists)-1]
+		w.marker = bullet
+		if l.ordered {
+			w.marker = strconv.Itoa(l.next) + ". "
+			l.next++
+		}
+		w.prefixes = append(w.prefixes, strings.Repeat(" ", utf8.RuneCountInString(w.marker)))
+		w.blank = false
+	} else {
+		w.prefixes = w.prefixes[:len(w.prefixes)-1]
+		w.blank = !w.lists[len(w.lists)-1].tight
+	}
+}
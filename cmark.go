@@ -1,12 +1,29 @@
+//go:build cgo
+
 package cmark
 
-// #cgo LDFLAGS: -lcmark
 // #include <string.h>
 // #include <stdlib.h>
-// #include <cmark.h>
+// #include "cmark_go.h"
+//
+// // go_cmark_safe_options sets CMARK_OPT_SAFE for cmark before 0.29,
+// // and clears CMARK_OPT_UNSAFE for later versions, where safe is the
+// // default and CMARK_OPT_SAFE is ignored
+// static int go_cmark_safe_options(int options) {
+//   options |= CMARK_OPT_SAFE;
+// #ifdef CMARK_OPT_UNSAFE
+//   options &= ~CMARK_OPT_UNSAFE;
+// #endif
+//   return options;
+// }
 import "C"
 import (
+	"bytes"
+	"context"
 	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"unsafe"
 )
 
@@ -15,6 +32,100 @@ type Parser struct {
 	parser *C.cmark_parser
 }
 
+// parserLimits are the input limits set on a parser, and the state of
+// the document being written needed to enforce them
+type parserLimits struct {
+	maxLine  int
+	maxInput int64
+	// line is the length of the last, unfinished, line written
+	line int
+	// written is the number of bytes written for this document
+	written int64
+}
+
+var (
+	// limitsByParser maps parser pointers to their *parserLimits
+	limitsByParser sync.Map
+	// limitsUsed is set once a limit has been set,
+	// so that Write only looks up limits when there may be some
+	limitsUsed int32
+)
+
+// limits returns the limits of p, or nil if it has none
+func (p Parser) limits() *parserLimits {
+	if atomic.LoadInt32(&limitsUsed) == 0 {
+		return nil
+	}
+	l, _ := limitsByParser.Load(uintptr(unsafe.Pointer(p.parser)))
+	lim, _ := l.(*parserLimits)
+	return lim
+}
+
+// setLimits creates limits for p if it has none and passes them to set
+func (p Parser) setLimits(set func(l *parserLimits)) {
+	atomic.StoreInt32(&limitsUsed, 1)
+	l, _ := limitsByParser.LoadOrStore(uintptr(unsafe.Pointer(p.parser)), &parserLimits{})
+	set(l.(*parserLimits))
+}
+
+// check returns an error if writing b would exceed a limit, and
+// otherwise records b as written
+func (l *parserLimits) check(b []byte) error {
+	if l.maxInput > 0 && l.written+int64(len(b)) > l.maxInput {
+		return ErrInputTooLarge
+	}
+	size, line := len(b), l.line
+	for len(b) > 0 {
+		i := bytes.IndexByte(b, '\n')
+		if i < 0 {
+			line += len(b)
+			break
+		}
+		if l.maxLine > 0 && line+i > l.maxLine {
+			return ErrLineTooLong
+		}
+		line, b = 0, b[i+1:]
+	}
+	if l.maxLine > 0 && line > l.maxLine {
+		return ErrLineTooLong
+	}
+	l.line = line
+	l.written += int64(size)
+	return nil
+}
+
+// finish readies l for the next document
+func (l *parserLimits) finish() {
+	l.line, l.written = 0, 0
+}
+
+// SetMaxLineLength makes Write fail with ErrLineTooLong, writing
+// nothing, when a line of the document would be longer than n bytes,
+// not counting the line ending
+// 0 removes the limit
+// ParseBytes and ParseString ignore the error, use ParseBytesChecked,
+// ParseStringChecked or Write to see it
+func (p Parser) SetMaxLineLength(n int) error {
+	if n < 0 {
+		return errors.New("Maximum line length must not be negative")
+	}
+	p.setLimits(func(l *parserLimits) { l.maxLine = n })
+	return nil
+}
+
+// SetMaxInputSize makes Write fail with ErrInputTooLarge, writing
+// nothing, when the document written would be larger than n bytes
+// 0 removes the limit
+// ParseBytes and ParseString ignore the error, use ParseBytesChecked,
+// ParseStringChecked or Write to see it
+func (p Parser) SetMaxInputSize(n int64) error {
+	if n < 0 {
+		return errors.New("Maximum input size must not be negative")
+	}
+	p.setLimits(func(l *parserLimits) { l.maxInput = n })
+	return nil
+}
+
 // Opt CommonMark options
 type Opt C.int
 
@@ -31,11 +142,16 @@ const (
 // NewParser builds a parser with the given options
 // when finished call Close
 func NewParser(options Opt) Parser {
-	return Parser{parser: C.cmark_parser_new(C.int(options))}
+	return Parser{parser: newParser(options)}
 }
 
 // Write bytes to the parser using the streaming interface
 func (p Parser) Write(b []byte) (n int, err error) {
+	if l := p.limits(); l != nil {
+		if err := l.check(b); err != nil {
+			return 0, err
+		}
+	}
 	buf := C.CBytes(b)
 	sz := len(b)
 	C.cmark_parser_feed(p.parser, (*C.char)(buf), C.size_t(sz))
@@ -43,14 +159,145 @@ func (p Parser) Write(b []byte) (n int, err error) {
 	return sz, nil
 }
 
+// WriteString writes a string to the parser without copying it,
+// cmark copies what it needs before returning
+func (p Parser) WriteString(s string) (n int, err error) {
+	if len(s) == 0 {
+		return 0, nil
+	}
+	if l := p.limits(); l != nil {
+		if err := l.check(unsafe.Slice(unsafe.StringData(s), len(s))); err != nil {
+			return 0, err
+		}
+	}
+	C.cmark_parser_feed(p.parser, (*C.char)(unsafe.Pointer(unsafe.StringData(s))), C.size_t(len(s)))
+	return len(s), nil
+}
+
+// contextChunkSize is how many bytes WriteContext writes between
+// checks for cancellation
+const contextChunkSize = 64 * 1024
+
+// WriteContext writes b to the parser in chunks, stopping early
+// with ctx.Err() if ctx is cancelled between chunks, or with the error
+// of a chunk which could not be written
+func (p Parser) WriteContext(ctx context.Context, b []byte) (n int, err error) {
+	for n < len(b) {
+		if err := ctx.Err(); err != nil {
+			return n, err
+		}
+		end := n + contextChunkSize
+		if end > len(b) {
+			end = len(b)
+		}
+		w, err := p.Write(b[n:end])
+		n += w
+		if err != nil {
+			return n, err
+		}
+	}
+	return n, nil
+}
+
 // Tree returns the root node for the generated document
 // Call this method only once, and then call Close
 func (p Parser) Tree() Node {
+	if l := p.limits(); l != nil {
+		l.finish()
+	}
 	return Node{node: C.cmark_parser_finish(p.parser)}
 }
 
+// ParseBytes writes b to the parser and returns the finished document
+// cmark readies the parser for the next document once it is finished,
+// so the parser may be used again
+//
+// The error of Write is dropped: if b exceeds a limit set with
+// SetMaxLineLength or SetMaxInputSize nothing of it is written and the
+// document holds only what was written before, use ParseBytesChecked
+// to see the error
+func (p Parser) ParseBytes(b []byte) Node {
+	p.Write(b)
+	return p.Tree()
+}
+
+// ParseString is ParseBytes for a string, it also drops the error of
+// a limit being exceeded, use ParseStringChecked to see it
+func (p Parser) ParseString(s string) Node {
+	p.WriteString(s)
+	return p.Tree()
+}
+
+// ParseBytesChecked is ParseBytes returning the error of Write,
+// ErrLineTooLong or ErrInputTooLarge when b exceeds a limit
+// On error the unfinished document is freed, no document is returned
+// and the parser is ready for the next document
+func (p Parser) ParseBytesChecked(b []byte) (Node, error) {
+	_, err := p.Write(b)
+	return p.checkedTree(err)
+}
+
+// ParseStringChecked is ParseBytesChecked for a string
+func (p Parser) ParseStringChecked(s string) (Node, error) {
+	_, err := p.WriteString(s)
+	return p.checkedTree(err)
+}
+
+// checkedTree finishes the document, freeing it if err is not nil
+func (p Parser) checkedTree(err error) (Node, error) {
+	doc := p.Tree()
+	if err != nil {
+		doc.Close()
+		return Node{}, err
+	}
+	return doc, nil
+}
+
+// ParseAndRenderHTML renders html from markdown in one call, without
+// building a tree which could be modified in between
+// When built against cmark it uses cmark_markdown_to_html
+func ParseAndRenderHTML(markdown string, opts Opt) string {
+	html := markdownToHTML((*C.char)(unsafe.Pointer(unsafe.StringData(markdown))), C.size_t(len(markdown)), opts)
+	gstr := C.GoString(html)
+	C.free(unsafe.Pointer(html))
+	return gstr
+}
+
+// MarkdownToHTML renders html from markdown with
+// cmark_markdown_to_html, which parses and renders in one pass
+// Built with cmark_gfm the registered extensions are used, which
+// needs a separate parse and render
+func MarkdownToHTML(markdown []byte, options Opt) string {
+	var text *C.char
+	if len(markdown) > 0 {
+		text = (*C.char)(unsafe.Pointer(&markdown[0]))
+	}
+	html := markdownToHTML(text, C.size_t(len(markdown)), options)
+	gstr := C.GoString(html)
+	C.free(unsafe.Pointer(html))
+	return gstr
+}
+
+// Reset discards any input written so far and readies the parser
+// for a new document with the given options
+// cmark has no reset function so the wrapped parser is reallocated
+func (p *Parser) Reset(options Opt) {
+	l := p.limits()
+	p.Close()
+	p.parser = newParser(options)
+	if l != nil {
+		l.finish()
+		limitsByParser.Store(uintptr(unsafe.Pointer(p.parser)), l)
+	}
+}
+
 // Close frees the wrapped CommonMark Parser
+// A document written but not yet returned by Tree is freed with it,
+// so closing a parser without calling Tree does not leak
 func (p Parser) Close() {
+	if atomic.LoadInt32(&limitsUsed) != 0 {
+		limitsByParser.Delete(uintptr(unsafe.Pointer(p.parser)))
+	}
 	C.cmark_parser_free(p.parser)
 }
 
@@ -58,6 +305,19 @@ type Node struct {
 	node *C.cmark_node
 }
 
+// IsNil returns true if the node does not point to a cmark node,
+// as returned by navigation methods such as Next when there is no
+// such node
+func (n Node) IsNil() bool {
+	return n.node == nil
+}
+
+// NewNode creates a new node of the given type
+// the node is not part of any tree, call Close if it is never linked
+func NewNode(typ NodeType) Node {
+	return Node{node: C.cmark_node_new(C.cmark_node_type(typ))}
+}
+
 // NodeType contains the type of a CommonMark AST node
 type NodeType C.cmark_node_type
 
@@ -77,8 +337,8 @@ const (
 	NodeHeading       = C.CMARK_NODE_HEADING
 	NodeThematicBreak = C.CMARK_NODE_THEMATIC_BREAK
 
-	NodeFirstBlock = C.CMARK_NODE_FIRST_BLOCK
-	NodeLastBlock  = C.CMARK_NODE_LAST_BLOCK
+	NodeFirstBlock = C.GO_CMARK_NODE_FIRST_BLOCK
+	NodeLastBlock  = C.GO_CMARK_NODE_LAST_BLOCK
 
 	// Inline types
 
@@ -93,8 +353,8 @@ const (
 	NodeLink         = C.CMARK_NODE_LINK
 	NodeImage        = C.CMARK_NODE_IMAGE
 
-	NodeFirstInline = C.CMARK_NODE_FIRST_INLINE
-	NodeLastInline  = C.CMARK_NODE_LAST_INLINE
+	NodeFirstInline = C.GO_CMARK_NODE_FIRST_INLINE
+	NodeLastInline  = C.GO_CMARK_NODE_LAST_INLINE
 )
 
 func (n Node) Next() Node {
@@ -117,16 +377,141 @@ func (n Node) LastChild() Node {
 	return Node{node: C.cmark_node_last_child(n.node)}
 }
 
+// HasChildren returns true if the node has at least one child
+func (n Node) HasChildren() bool {
+	return C.cmark_node_first_child(n.node) != nil
+}
+
+// FirstLeaf returns the first descendant without children reached by
+// following FirstChild, or the node itself if it has no children
+func (n Node) FirstLeaf() Node {
+	for c := n.FirstChild(); c.node != nil; c = c.FirstChild() {
+		n = c
+	}
+	return n
+}
+
+// LastLeaf returns the last descendant without children reached by
+// following LastChild, or the node itself if it has no children
+func (n Node) LastLeaf() Node {
+	for c := n.LastChild(); c.node != nil; c = c.LastChild() {
+		n = c
+	}
+	return n
+}
+
+// Depth returns the number of ancestors of a node,
+// 0 for the document root
+func (n Node) Depth() int {
+	depth := 0
+	for p := C.cmark_node_parent(n.node); p != nil; p = C.cmark_node_parent(p) {
+		depth++
+	}
+	return depth
+}
+
+// SiblingsBefore returns the nodes before this one with the same parent,
+// in document order
+func (n Node) SiblingsBefore() []Node {
+	var nodes []Node
+	for s := n.Previous(); s.node != nil; s = s.Previous() {
+		nodes = append(nodes, s)
+	}
+	for i, j := 0, len(nodes)-1; i < j; i, j = i+1, j-1 {
+		nodes[i], nodes[j] = nodes[j], nodes[i]
+	}
+	return nodes
+}
+
+// SiblingsAfter returns the nodes after this one with the same parent,
+// in document order
+func (n Node) SiblingsAfter() []Node {
+	var nodes []Node
+	for s := n.Next(); s.node != nil; s = s.Next() {
+		nodes = append(nodes, s)
+	}
+	return nodes
+}
+
+// Siblings returns all children of this node's parent in document order,
+// including this node
+func (n Node) Siblings() []Node {
+	nodes := append(n.SiblingsBefore(), n)
+	return append(nodes, n.SiblingsAfter()...)
+}
+
 // UserData returns the UserData associated with a node
+//
+// This is unsafe and intended for C interop, u must not point to Go
+// memory, see GoData for storing Go values
 func (n Node) UserData() unsafe.Pointer {
 	return C.cmark_node_get_user_data(n.node)
 }
 
 // SetUserData sets the UserData associated with a node
+//
+// This is unsafe and intended for C interop, u must not point to Go
+// memory, see SetGoData for storing Go values
 func (n Node) SetUserData(u unsafe.Pointer) {
 	C.cmark_node_set_user_data(n.node, u)
 }
 
+var (
+	// goData maps node pointers to values set with SetGoData
+	goData sync.Map
+	// goDataUsed is set once SetGoData has been called,
+	// so that trees are only walked when there may be data to remove
+	goDataUsed int32
+)
+
+// forgetGoData removes the Go values of the subtree of n,
+// which is about to be freed
+func forgetGoData(n Node) {
+	if atomic.LoadInt32(&goDataUsed) == 0 {
+		return
+	}
+	Walk(n, func(c Node, ev Event) error {
+		goData.Delete(uintptr(unsafe.Pointer(c.node)))
+		return nil
+	})
+}
+
+// forgetMergedText removes the Go values of the text nodes which
+// cmark_consolidate_text_nodes frees when called on n, those following
+// another text node
+func forgetMergedText(n Node) {
+	if atomic.LoadInt32(&goDataUsed) == 0 {
+		return
+	}
+	Walk(n, func(c Node, ev Event) error {
+		if typ, _ := c.Type(); typ != NodeText {
+			return nil
+		}
+		for next := c.Next(); next.node != nil; next = next.Next() {
+			if typ, _ := next.Type(); typ != NodeText {
+				break
+			}
+			goData.Delete(uintptr(unsafe.Pointer(next.node)))
+		}
+		return nil
+	})
+}
+
+// GoData returns the Go value associated with a node by SetGoData,
+// or nil
+func (n Node) GoData() interface{} {
+	v, _ := goData.Load(uintptr(unsafe.Pointer(n.node)))
+	return v
+}
+
+// SetGoData associates a Go value with a node,
+// the value is released when the node is freed, by Close or by
+// ConsolidateTextNodes merging it into the text node before it
+func (n Node) SetGoData(v interface{}) {
+	atomic.StoreInt32(&goDataUsed, 1)
+	goData.Store(uintptr(unsafe.Pointer(n.node)), v)
+}
+
 func (n Node) Type() (NodeType, error) {
 	typ := NodeType(C.cmark_node_get_type(n.node))
 	if typ == NodeNone {
@@ -135,6 +520,28 @@ func (n Node) Type() (NodeType, error) {
 	return typ, nil
 }
 
+// IsBlock returns true if the node is a block node
+func (n Node) IsBlock() bool {
+	return isBlockType(NodeType(C.cmark_node_get_type(n.node)))
+}
+
+// IsInline returns true if the node is an inline node
+func (n Node) IsInline() bool {
+	return isInlineType(NodeType(C.cmark_node_get_type(n.node)))
+}
+
+// IsLeaf returns true if the node type can never have children
+// (text, code, soft and line breaks, inline html, and the code, html
+// and thematic break blocks)
+func (n Node) IsLeaf() bool {
+	switch NodeType(C.cmark_node_get_type(n.node)) {
+	case NodeText, NodeCode, NodeSoftBreak, NodeLineBreak, NodeHTMLInline,
+		NodeCodeBlock, NodeHTMLBlock, NodeThematicBreak:
+		return true
+	}
+	return false
+}
+
 // TypeString returns a string for a node's type or "<unknown>" on error
 func (n Node) TypeString() string {
 	str := C.cmark_node_get_type_string(n.node)
@@ -148,12 +555,28 @@ func (n Node) Literal() string {
 }
 
 // SetLiteral overwrites the literal with a string
-// the old string, if any, is not freed
+// cmark literals are C strings, so the literal ends at the first NUL
+// byte of lit
 func (n Node) SetLiteral(lit string) {
-	C.cmark_node_set_literal(n.node, C.CString(lit))
+	clit := C.CString(lit)
+	C.cmark_node_set_literal(n.node, clit)
+	C.free(unsafe.Pointer(clit))
+}
+
+// SetLiteralBytes is a convenience wrapper which overwrites the
+// literal with the contents of b, as SetLiteral(string(b)) would
+// without converting b to a string first
+// Like SetLiteral the literal ends at the first NUL byte of b
+func (n Node) SetLiteralBytes(b []byte) {
+	buf := (*C.char)(C.malloc(C.size_t(len(b) + 1)))
+	dst := unsafe.Slice((*byte)(unsafe.Pointer(buf)), len(b)+1)
+	copy(dst, b)
+	dst[len(b)] = 0
+	C.cmark_node_set_literal(n.node, buf)
+	C.free(unsafe.Pointer(buf))
 }
 
-// HeadingLevel returns the heading level of a node
+// HeadingLevel returns the heading level of a node from 1 to 6
 // e.g. 1 for an h1, etc., or 0 if this node is not a heading
 func (n Node) HeadingLevel() (int, error) {
 	level := int(C.cmark_node_get_heading_level(n.node))
@@ -163,6 +586,33 @@ func (n Node) HeadingLevel() (int, error) {
 	return level, nil
 }
 
+// SetHeadingText replaces all children of a heading with a single
+// text node containing text
+// On failure the original children are restored
+func (n Node) SetHeadingText(text string) error {
+	if typ, _ := n.Type(); typ != NodeHeading {
+		return errors.New("Node is not a heading")
+	}
+	var old []Node
+	for c := n.FirstChild(); c.node != nil; c = n.FirstChild() {
+		c.Unlink()
+		old = append(old, c)
+	}
+	t := NewNode(NodeText)
+	t.SetLiteral(text)
+	if err := n.AppendChild(t); err != nil {
+		t.Close()
+		for _, c := range old {
+			n.AppendChild(c)
+		}
+		return err
+	}
+	for _, c := range old {
+		c.Close()
+	}
+	return nil
+}
+
 type ListType C.cmark_list_type
 
 const (
@@ -189,7 +639,7 @@ func (n Node) ListType() (ListType, error) {
 
 func (n Node) SetListType(typ ListType) error {
 	if C.cmark_node_set_list_type(n.node, C.cmark_list_type(typ)) == 0 {
-		return errors.New("List type could not be set")
+		return n.nodeError("SetListType", "node is not a list")
 	}
 	return nil
 }
@@ -204,23 +654,24 @@ func (n Node) ListDelim() (ListDelim, error) {
 
 func (n Node) SetListDelim(typ ListDelim) error {
 	if C.cmark_node_set_list_delim(n.node, C.cmark_delim_type(typ)) == 0 {
-		return errors.New("List type could not be set")
+		return n.nodeError("SetListDelim", "node is not a list")
 	}
 	return nil
 }
 
+// ListStart returns the start number of an ordered list,
+// which may be 0
 func (n Node) ListStart() (int, error) {
-	start := int(C.cmark_node_get_list_start(n.node))
-	if start == 0 {
-		return start, errors.New("ListStart can only be called on ordered lists")
+	if NodeType(C.cmark_node_get_type(n.node)) != NodeList {
+		return 0, errors.New("Node is not a list")
 	}
-	return start, nil
+	return int(C.cmark_node_get_list_start(n.node)), nil
 }
 
 // SetListStart sets the list start number for an ordered list
 func (n Node) SetListStart(start int) error {
 	if C.cmark_node_set_list_start(n.node, C.int(start)) == 0 {
-		return errors.New("SetListStart failed")
+		return n.nodeError("SetListStart", "node is not a list or start is negative")
 	}
 	return nil
 }
@@ -236,7 +687,7 @@ func (n Node) SetTightList(tight bool) error {
 		t = 1
 	}
 	if C.cmark_node_set_list_tight(n.node, C.int(t)) == 0 {
-		return errors.New("SetTightList failed")
+		return n.nodeError("SetTightList", "node is not a list")
 	}
 	return nil
 }
@@ -248,8 +699,31 @@ func (n Node) FenceInfo() string {
 }
 
 func (n Node) SetFenceInfo(fence string) error {
-	if C.cmark_node_set_fence_info(n.node, C.CString(fence)) == 0 {
-		return errors.New("SetFenceInfo failed")
+	cfence := C.CString(fence)
+	defer C.free(unsafe.Pointer(cfence))
+	if C.cmark_node_set_fence_info(n.node, cfence) == 0 {
+		return n.nodeError("SetFenceInfo", "node is not a code block")
+	}
+	return nil
+}
+
+// FenceLength returns the number of backticks or tildes in the opening
+// fence of a fenced code block, or -1 if the node is not a fenced code
+// block or the fence length is not available
+// Only cmark-gfm, built with the cmark_gfm tag, records fence lengths
+// (cmark-gfm 0.29 and later provide cmark_node_get_fenced)
+func (n Node) FenceLength() int {
+	return fenceLength(n.node)
+}
+
+// SetFenceLength sets the opening fence length of a fenced code block,
+// it fails when built against cmark rather than cmark-gfm
+func (n Node) SetFenceLength(length int) error {
+	if length < 3 {
+		return n.nodeError("SetFenceLength", "fences are at least 3 characters long")
+	}
+	if !setFenceLength(n.node, length) {
+		return n.nodeError("SetFenceLength", fenceLengthUnsupported)
 	}
 	return nil
 }
@@ -259,8 +733,10 @@ func (n Node) URL() string {
 }
 
 func (n Node) SetURL(url string) error {
-	if C.cmark_node_set_url(n.node, C.CString(url)) == 0 {
-		return errors.New("SetURL failed")
+	curl := C.CString(url)
+	defer C.free(unsafe.Pointer(curl))
+	if C.cmark_node_set_url(n.node, curl) == 0 {
+		return n.nodeError("SetURL", "node is not a link or image")
 	}
 	return nil
 }
@@ -270,8 +746,10 @@ func (n Node) Title() string {
 }
 
 func (n Node) SetTitle(title string) error {
-	if C.cmark_node_set_title(n.node, C.CString(title)) == 0 {
-		return errors.New("SetTitle failed")
+	ctitle := C.CString(title)
+	defer C.free(unsafe.Pointer(ctitle))
+	if C.cmark_node_set_title(n.node, ctitle) == 0 {
+		return n.nodeError("SetTitle", "node is not a link or image")
 	}
 	return nil
 }
@@ -281,7 +759,9 @@ func (n Node) OnEnter() string {
 }
 
 func (n Node) SetOnEnter(onEnter string) error {
-	if C.cmark_node_set_on_enter(n.node, C.CString(onEnter)) == 0 {
+	cOnEnter := C.CString(onEnter)
+	defer C.free(unsafe.Pointer(cOnEnter))
+	if C.cmark_node_set_on_enter(n.node, cOnEnter) == 0 {
 		return errors.New("SetOnEnter failed")
 	}
 	return nil
@@ -292,12 +772,24 @@ func (n Node) OnExit() string {
 }
 
 func (n Node) SetOnExit(onExit string) error {
-	if C.cmark_node_set_on_exit(n.node, C.CString(onExit)) == 0 {
+	cOnExit := C.CString(onExit)
+	defer C.free(unsafe.Pointer(cOnExit))
+	if C.cmark_node_set_on_exit(n.node, cOnExit) == 0 {
 		return errors.New("SetOnExit failed")
 	}
 	return nil
 }
 
+// Range returns the source range of the node, see OptSourcePos
+func (n Node) Range() SourceRange {
+	return SourceRange{
+		StartLine:   int(C.cmark_node_get_start_line(n.node)),
+		StartColumn: int(C.cmark_node_get_start_column(n.node)),
+		EndLine:     int(C.cmark_node_get_end_line(n.node)),
+		EndColumn:   int(C.cmark_node_get_end_column(n.node)),
+	}
+}
+
 func (n Node) StartLine() int {
 	return int(C.cmark_node_get_start_line(n.node))
 }
@@ -322,14 +814,14 @@ func (n Node) Unlink() {
 
 func (n Node) InsertBefore(s Node) error {
 	if C.cmark_node_insert_before(n.node, s.node) == 0 {
-		return errors.New("InsertBefore failed")
+		return n.nodeError("InsertBefore", "node cannot be a sibling of this node")
 	}
 	return nil
 }
 
 func (n Node) InsertAfter(s Node) error {
 	if C.cmark_node_insert_after(n.node, s.node) == 0 {
-		return errors.New("InsertAfter failed")
+		return n.nodeError("InsertAfter", "node cannot be a sibling of this node")
 	}
 	return nil
 }
@@ -338,21 +830,21 @@ func (n Node) InsertAfter(s Node) error {
 // call Close on the old node if no longer needed
 func (o Node) Replace(n Node) error {
 	if C.cmark_node_replace(o.node, n.node) == 0 {
-		return errors.New("Replace failed")
+		return o.nodeError("Replace", "node cannot replace this node")
 	}
 	return nil
 }
 
 func (n Node) PrependChild(c Node) error {
 	if C.cmark_node_prepend_child(n.node, c.node) == 0 {
-		return errors.New("PrependChild failed")
+		return n.nodeError("PrependChild", "node cannot be a child of this node")
 	}
 	return nil
 }
 
 func (n Node) AppendChild(c Node) error {
 	if C.cmark_node_append_child(n.node, c.node) == 0 {
-		return errors.New("AppendChild failed")
+		return n.nodeError("AppendChild", "node cannot be a child of this node")
 	}
 	return nil
 }
@@ -360,36 +852,80 @@ func (n Node) AppendChild(c Node) error {
 // ConsolidateTextNodes consolidates adjacent text nodes into one text node
 // for the sub-tree of this node
 func (n Node) ConsolidateTextNodes() {
+	forgetMergedText(n)
 	C.cmark_consolidate_text_nodes(n.node)
 }
 
+// Normalize tidies a document after it has been modified,
+// it must be called on the document root
+// Currently this consolidates adjacent text nodes, the only
+// normalization cmark provides
+func (n Node) Normalize() error {
+	if NodeType(C.cmark_node_get_type(n.node)) != NodeDocument {
+		return errors.New("Normalize must be called on a document")
+	}
+	n.ConsolidateTextNodes()
+	return nil
+}
+
 // SetHeadingLevel sets heading level to value (1 for h1, etc.)
+// valid levels are 1 to 6, for others ErrInvalidHeadingLevel is
+// returned before cmark is called, so the node is left unchanged
 func (n Node) SetHeadingLevel(level int) error {
+	if level < 1 || level > 6 {
+		return ErrInvalidHeadingLevel
+	}
 	if C.cmark_node_set_heading_level(n.node, C.int(level)) == 0 {
-		return errors.New("Heading could not be set")
+		return n.nodeError("SetHeadingLevel", "node is not a heading")
 	}
 	return nil
 }
 
 // Close frees the wrapped CommonMark Node
 func (n Node) Close() {
+	forgetGoData(n)
 	C.cmark_node_free(n.node)
 }
 
 // RenderHTML renders html from the document
 func (n Node) RenderHTML(options Opt) string {
-	html := C.cmark_render_html(n.node, C.int(options))
+	html := renderHTML(n.node, C.int(options))
 	gstr := C.GoString(html)
-	C.free(unsafe.Pointer(html))
+	freeRendered(n.node, html)
 	return gstr
 }
 
+// RenderHTMLSafe renders html from the document with OptSafe set and
+// the unsafe option of cmark 0.29 and later cleared, so raw html and
+// dangerous URLs (javascript:, vbscript:, file: and most data:) are
+// never output
+// Use this to render untrusted documents
+func (n Node) RenderHTMLSafe(options Opt) string {
+	return n.RenderHTML(Opt(C.go_cmark_safe_options(C.int(options))))
+}
+
+// RenderHTMLFragment renders html for embedding in an existing element
+// The content of a paragraph or heading is rendered without the
+// enclosing <p> or <hN> tag, other nodes are rendered as by RenderHTML
+// without the trailing newline
+func (n Node) RenderHTMLFragment(options Opt) string {
+	typ, _ := n.Type()
+	if typ != NodeParagraph && typ != NodeHeading {
+		return strings.TrimSuffix(n.RenderHTML(options), "\n")
+	}
+	var b strings.Builder
+	for c := n.FirstChild(); c.node != nil; c = c.Next() {
+		b.WriteString(c.RenderHTML(options))
+	}
+	return b.String()
+}
+
 // RenderXML renders xml from the document
 // This rendering is basically a serialization of the AST
 func (n Node) RenderXML(options Opt) string {
 	xml := C.cmark_render_xml(n.node, C.int(options))
 	gstr := C.GoString(xml)
-	C.free(unsafe.Pointer(xml))
+	freeRendered(n.node, xml)
 	return gstr
 }
 
@@ -398,7 +934,7 @@ func (n Node) RenderXML(options Opt) string {
 func (n Node) RenderMan(options Opt, wrapWidth int) string {
 	man := C.cmark_render_man(n.node, C.int(options), C.int(wrapWidth))
 	gstr := C.GoString(man)
-	C.free(unsafe.Pointer(man))
+	freeRendered(n.node, man)
 	return gstr
 }
 
@@ -407,7 +943,7 @@ func (n Node) RenderMan(options Opt, wrapWidth int) string {
 func (n Node) RenderLaTeX(options Opt, wrapWidth int) string {
 	latex := C.cmark_render_latex(n.node, C.int(options), C.int(wrapWidth))
 	gstr := C.GoString(latex)
-	C.free(unsafe.Pointer(latex))
+	freeRendered(n.node, latex)
 	return gstr
 }
 
@@ -419,10 +955,21 @@ func (n Node) RenderLaTeX(options Opt, wrapWidth int) string {
 func (n Node) RenderCommonMark(options Opt, wrapWidth int) string {
 	markdown := C.cmark_render_commonmark(n.node, C.int(options), C.int(wrapWidth))
 	gstr := C.GoString(markdown)
-	C.free(unsafe.Pointer(markdown))
+	freeRendered(n.node, markdown)
 	return gstr
 }
 
+// ToCommonMarkString renders CommonMark with the default options,
+// wrapped at 80 columns
+func (n Node) ToCommonMarkString() string {
+	return n.RenderCommonMark(OptDefault, 80)
+}
+
+// ToHTMLString renders html with the default options
+func (n Node) ToHTMLString() string {
+	return n.RenderHTML(OptDefault)
+}
+
 type Event C.cmark_event_type
 
 const (
@@ -467,8 +1014,33 @@ func (i Iter) Root() Node {
 
 // Reset resets the iterator to a node and event
 // Node must be a child of the root
+//
+// Deprecated: Use ResetTo, which reports nodes outside the root's tree.
+// Reset ignores such nodes.
 func (i Iter) Reset(n Node, e Event) {
+	i.ResetTo(n, e)
+}
+
+// ResetTo resets the iterator to a node and event
+// An error is returned, and the iterator left unchanged,
+// if the node is not the root or one of its descendants
+func (i Iter) ResetTo(n Node, e Event) error {
+	root := C.cmark_iter_get_root(i.iter)
+	p := n.node
+	for p != nil && p != root {
+		p = C.cmark_node_parent(p)
+	}
+	if p == nil {
+		return errors.New("Node is not in the iterator's tree")
+	}
 	C.cmark_iter_reset(i.iter, n.node, C.cmark_event_type(e))
+	return nil
+}
+
+// Skip makes the next call to Next skip the children of the current
+// node, as if its EventExit had just been returned
+func (i Iter) Skip() {
+	C.cmark_iter_reset(i.iter, C.cmark_iter_get_node(i.iter), C.CMARK_EVENT_EXIT)
 }
 
 func (i Iter) Close() {
@@ -0,0 +1,28 @@
+// cmark_go.h includes the cmark or cmark-gfm header,
+// GO_CMARK_GFM is defined when building with the cmark_gfm tag
+#ifndef CMARK_GO_H
+#define CMARK_GO_H
+
+#ifdef GO_CMARK_GFM
+#include <cmark-gfm.h>
+// cmark-gfm assigns extension node types at startup, after the core
+// types, so the ranges below only hold the core types
+#define GO_CMARK_NODE_FIRST_BLOCK CMARK_NODE_DOCUMENT
+#define GO_CMARK_NODE_LAST_BLOCK CMARK_NODE_FOOTNOTE_DEFINITION
+#define GO_CMARK_NODE_FIRST_INLINE CMARK_NODE_TEXT
+#define GO_CMARK_NODE_LAST_INLINE CMARK_NODE_FOOTNOTE_REFERENCE
+#else
+#include <cmark.h>
+#define GO_CMARK_NODE_FIRST_BLOCK CMARK_NODE_FIRST_BLOCK
+#define GO_CMARK_NODE_LAST_BLOCK CMARK_NODE_LAST_BLOCK
+#define GO_CMARK_NODE_FIRST_INLINE CMARK_NODE_FIRST_INLINE
+#define GO_CMARK_NODE_LAST_INLINE CMARK_NODE_LAST_INLINE
+#endif
+
+// go_cmark_mem_free frees ptr with mem, cgo cannot call through the
+// function pointers of a cmark_mem
+static inline void go_cmark_mem_free(cmark_mem *mem, void *ptr) {
+  mem->free(ptr);
+}
+
+#endif
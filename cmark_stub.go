@@ -0,0 +1,596 @@
+//go:build !cgo
+
+package cmark
+
+// This file lets packages which import cmark build without cgo,
+// it mirrors the API of the cgo files with operations which do nothing,
+// returning zero values and ErrCGONotAvailable
+
+import (
+	"context"
+	"io"
+	"unsafe"
+)
+
+type Parser struct{}
+
+type Opt int
+
+// Values from cmark.h
+const (
+	OptDefault      Opt = 0
+	OptSourcePos        = 1 << 1
+	OptHardBreaks       = 1 << 2
+	OptSafe             = 1 << 3
+	OptNoBreaks         = 1 << 4
+	OptValidateUtf8     = 1 << 9
+	OptSmart            = 1 << 10
+)
+
+type Node struct {
+	node unsafe.Pointer
+}
+
+type NodeType int
+
+const (
+	NodeNone NodeType = iota
+
+	NodeDocument
+	NodeBlockQuote
+	NodeList
+	NodeItem
+	NodeCodeBlock
+	NodeHTMLBlock
+	NodeCustomBlock
+	NodeParagraph
+	NodeHeading
+	NodeThematicBreak
+
+	NodeText
+	NodeSoftBreak
+	NodeLineBreak
+	NodeCode
+	NodeHTMLInline
+	NodeCustomInline
+	NodeEmph
+	NodeStrong
+	NodeLink
+	NodeImage
+)
+
+const (
+	NodeFirstBlock  = NodeDocument
+	NodeLastBlock   = NodeThematicBreak
+	NodeFirstInline = NodeText
+	NodeLastInline  = NodeImage
+)
+
+type ListType int
+
+const (
+	_NoList ListType = iota
+	BulletList
+	OrderedList
+)
+
+type ListDelim int
+
+const (
+	_NoDelim ListDelim = iota
+	PeriodDelim
+	ParenDelim
+)
+
+type Event int
+
+const (
+	EventNone Event = iota
+	EventDone
+	EventEnter
+	EventExit
+)
+
+type Iter struct{}
+
+type HTMLReader struct{}
+
+type NodeRenderer struct {
+	Node    Node
+	Options Opt
+}
+
+type Allocator interface {
+	Alloc(size int) unsafe.Pointer
+	Realloc(ptr unsafe.Pointer, size int) unsafe.Pointer
+	Free(ptr unsafe.Pointer)
+}
+
+type Mem struct{}
+
+type ArenaAllocator struct{}
+
+func isExtensionNodeType(t NodeType) bool {
+	return false
+}
+
+func isBlockType(t NodeType) bool {
+	return t >= NodeFirstBlock && t <= NodeLastBlock
+}
+
+func isInlineType(t NodeType) bool {
+	return t >= NodeFirstInline && t <= NodeLastInline
+}
+
+func extensionCanContain(parent, child NodeType) (can, ok bool) {
+	return false, false
+}
+
+func newNodeLike(n Node, typ NodeType) Node {
+	return Node{}
+}
+
+func copyExtensionAttributes(dst, src Node, typ NodeType) error {
+	return nil
+}
+
+func extensionNodeTypes() []NodeType {
+	return nil
+}
+
+func extensionTypeName(t NodeType) string {
+	return "<unknown>"
+}
+
+func newNodeFor(typ NodeType, a nodeAttributes) Node {
+	return Node{}
+}
+
+func extensionAttributes(n Node, typ NodeType, a *nodeAttributes) {
+}
+
+func setExtensionAttributes(n Node, typ NodeType, a nodeAttributes) error {
+	return nil
+}
+
+func NewParser(options Opt) Parser {
+	return Parser{}
+}
+
+func (p Parser) Write(b []byte) (n int, err error) {
+	return 0, ErrCGONotAvailable
+}
+
+func (p Parser) WriteString(s string) (n int, err error) {
+	return 0, ErrCGONotAvailable
+}
+
+func (p Parser) WriteContext(ctx context.Context, b []byte) (n int, err error) {
+	return 0, ErrCGONotAvailable
+}
+
+func (p Parser) Tree() Node {
+	return Node{}
+}
+
+func (p Parser) ParseBytes(b []byte) Node {
+	return Node{}
+}
+
+func (p Parser) ParseString(s string) Node {
+	return Node{}
+}
+
+func (p Parser) ParseBytesChecked(b []byte) (Node, error) {
+	return Node{}, ErrCGONotAvailable
+}
+
+func (p Parser) ParseStringChecked(s string) (Node, error) {
+	return Node{}, ErrCGONotAvailable
+}
+
+func ParseAndRenderHTML(markdown string, opts Opt) string {
+	return ""
+}
+
+func MarkdownToHTML(markdown []byte, options Opt) string {
+	return ""
+}
+
+func (p Parser) SetMaxLineLength(n int) error {
+	return ErrCGONotAvailable
+}
+
+func (p Parser) SetMaxInputSize(n int64) error {
+	return ErrCGONotAvailable
+}
+
+func (p *Parser) Reset(options Opt) {
+}
+
+func (p Parser) Close() {
+}
+
+func (n Node) IsNil() bool {
+	return n.node == nil
+}
+
+func NewNode(typ NodeType) Node {
+	return Node{}
+}
+
+func (n Node) Next() Node {
+	return Node{}
+}
+
+func (n Node) Previous() Node {
+	return Node{}
+}
+
+func (n Node) Parent() Node {
+	return Node{}
+}
+
+func (n Node) FirstChild() Node {
+	return Node{}
+}
+
+func (n Node) LastChild() Node {
+	return Node{}
+}
+
+func (n Node) HasChildren() bool {
+	return false
+}
+
+func (n Node) FirstLeaf() Node {
+	return Node{}
+}
+
+func (n Node) LastLeaf() Node {
+	return Node{}
+}
+
+func (n Node) Depth() int {
+	return 0
+}
+
+func (n Node) SiblingsBefore() []Node {
+	return nil
+}
+
+func (n Node) SiblingsAfter() []Node {
+	return nil
+}
+
+func (n Node) Siblings() []Node {
+	return nil
+}
+
+func (n Node) UserData() unsafe.Pointer {
+	return nil
+}
+
+func (n Node) SetUserData(u unsafe.Pointer) {
+}
+
+func (n Node) GoData() interface{} {
+	return nil
+}
+
+func (n Node) SetGoData(v interface{}) {
+}
+
+func (n Node) Type() (NodeType, error) {
+	return 0, ErrCGONotAvailable
+}
+
+func (n Node) IsBlock() bool {
+	return false
+}
+
+func (n Node) IsInline() bool {
+	return false
+}
+
+func (n Node) IsLeaf() bool {
+	return false
+}
+
+func (n Node) TypeString() string {
+	return ""
+}
+
+func (n Node) Literal() string {
+	return ""
+}
+
+func (n Node) SetLiteral(lit string) {
+}
+
+func (n Node) SetLiteralBytes(b []byte) {
+}
+
+func (n Node) HeadingLevel() (int, error) {
+	return 0, ErrCGONotAvailable
+}
+
+func (n Node) SetHeadingText(text string) error {
+	return ErrCGONotAvailable
+}
+
+func (n Node) ListType() (ListType, error) {
+	return 0, ErrCGONotAvailable
+}
+
+func (n Node) SetListType(typ ListType) error {
+	return ErrCGONotAvailable
+}
+
+func (n Node) ListDelim() (ListDelim, error) {
+	return 0, ErrCGONotAvailable
+}
+
+func (n Node) SetListDelim(typ ListDelim) error {
+	return ErrCGONotAvailable
+}
+
+func (n Node) ListStart() (int, error) {
+	return 0, ErrCGONotAvailable
+}
+
+func (n Node) SetListStart(start int) error {
+	return ErrCGONotAvailable
+}
+
+func (n Node) TightList() bool {
+	return false
+}
+
+func (n Node) SetTightList(tight bool) error {
+	return ErrCGONotAvailable
+}
+
+func (n Node) FenceInfo() string {
+	return ""
+}
+
+func (n Node) SetFenceInfo(fence string) error {
+	return ErrCGONotAvailable
+}
+
+func (n Node) FenceLength() int {
+	return 0
+}
+
+func (n Node) SetFenceLength(length int) error {
+	return ErrCGONotAvailable
+}
+
+func (n Node) URL() string {
+	return ""
+}
+
+func (n Node) SetURL(url string) error {
+	return ErrCGONotAvailable
+}
+
+func (n Node) Title() string {
+	return ""
+}
+
+func (n Node) SetTitle(title string) error {
+	return ErrCGONotAvailable
+}
+
+func (n Node) OnEnter() string {
+	return ""
+}
+
+func (n Node) SetOnEnter(onEnter string) error {
+	return ErrCGONotAvailable
+}
+
+func (n Node) OnExit() string {
+	return ""
+}
+
+func (n Node) SetOnExit(onExit string) error {
+	return ErrCGONotAvailable
+}
+
+func (n Node) Range() SourceRange {
+	return SourceRange{}
+}
+
+func (n Node) StartLine() int {
+	return 0
+}
+
+func (n Node) StartColumn() int {
+	return 0
+}
+
+func (n Node) EndtLine() int {
+	return 0
+}
+
+func (n Node) EndColumn() int {
+	return 0
+}
+
+func (n Node) Unlink() {
+}
+
+func (n Node) InsertBefore(s Node) error {
+	return ErrCGONotAvailable
+}
+
+func (n Node) InsertAfter(s Node) error {
+	return ErrCGONotAvailable
+}
+
+func (o Node) Replace(n Node) error {
+	return ErrCGONotAvailable
+}
+
+func (n Node) PrependChild(c Node) error {
+	return ErrCGONotAvailable
+}
+
+func (n Node) AppendChild(c Node) error {
+	return ErrCGONotAvailable
+}
+
+func (n Node) ConsolidateTextNodes() {
+}
+
+func (n Node) Normalize() error {
+	return ErrCGONotAvailable
+}
+
+func (n Node) SetHeadingLevel(level int) error {
+	return ErrCGONotAvailable
+}
+
+func (n Node) Close() {
+}
+
+func (n Node) RenderHTML(options Opt) string {
+	return ""
+}
+
+func (n Node) RenderHTMLSafe(options Opt) string {
+	return ""
+}
+
+func (n Node) RenderHTMLFragment(options Opt) string {
+	return ""
+}
+
+func (n Node) RenderXML(options Opt) string {
+	return ""
+}
+
+func (n Node) RenderMan(options Opt, wrapWidth int) string {
+	return ""
+}
+
+func (n Node) RenderLaTeX(options Opt, wrapWidth int) string {
+	return ""
+}
+
+func (n Node) RenderCommonMark(options Opt, wrapWidth int) string {
+	return ""
+}
+
+func (n Node) ToCommonMarkString() string {
+	return ""
+}
+
+func (n Node) ToHTMLString() string {
+	return ""
+}
+
+func (n Node) Iter() Iter {
+	return Iter{}
+}
+
+func (i Iter) Next() Event {
+	return EventDone
+}
+
+func (i Iter) Node() Node {
+	return Node{}
+}
+
+func (i Iter) Event() Event {
+	return EventDone
+}
+
+func (i Iter) Root() Node {
+	return Node{}
+}
+
+func (i Iter) Reset(n Node, e Event) {
+}
+
+func (i Iter) ResetTo(n Node, e Event) error {
+	return ErrCGONotAvailable
+}
+
+func (i Iter) Skip() {
+}
+
+func (i Iter) Close() {
+}
+
+func NewHTMLReader(n Node, opts Opt) *HTMLReader {
+	return nil
+}
+
+func (r *HTMLReader) Read(p []byte) (n int, err error) {
+	return 0, ErrCGONotAvailable
+}
+
+func (r *HTMLReader) WriteTo(w io.Writer) (n int64, err error) {
+	return 0, ErrCGONotAvailable
+}
+
+func (r *HTMLReader) Close() error {
+	return nil
+}
+
+func (n Node) WriteTo(w io.Writer) (int64, error) {
+	return 0, ErrCGONotAvailable
+}
+
+func (r NodeRenderer) WriteTo(w io.Writer) (int64, error) {
+	return 0, ErrCGONotAvailable
+}
+
+func DefaultMem() *Mem {
+	return nil
+}
+
+func NewMem(alloc Allocator) (*Mem, error) {
+	return nil, ErrCGONotAvailable
+}
+
+func (m *Mem) Close() error {
+	return nil
+}
+
+func NewCalloc() *Mem {
+	return nil
+}
+
+func ReleaseAllocator(a Allocator) {
+}
+
+func NewParserWithMem(options Opt, mem *Mem) Parser {
+	return Parser{}
+}
+
+func NewParserWithAllocator(opts Opt, alloc Allocator) (Parser, error) {
+	return Parser{}, ErrCGONotAvailable
+}
+
+func NewArenaAllocator(arenaSize int) *ArenaAllocator {
+	return &ArenaAllocator{}
+}
+
+func (a *ArenaAllocator) Alloc(size int) unsafe.Pointer {
+	return nil
+}
+
+func (a *ArenaAllocator) Realloc(ptr unsafe.Pointer, size int) unsafe.Pointer {
+	return nil
+}
+
+func (a *ArenaAllocator) Free(ptr unsafe.Pointer) {
+}
+
+func (a *ArenaAllocator) Close() error {
+	return nil
+}
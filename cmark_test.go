@@ -0,0 +1,510 @@
+//go:build cgo
+
+package cmark
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"unsafe"
+)
+
+func TestArenaAllocatorRender(t *testing.T) {
+	const md = "# Title\n\nSome *text*\n"
+	const want = "<h1>Title</h1>\n<p>Some <em>text</em></p>\n"
+	// more arenas than allocator slots, each Close frees its slot
+	for i := 0; i < 2*memSlots; i++ {
+		arena := NewArenaAllocator(1 << 16)
+		p, err := NewParserWithAllocator(OptDefault, arena)
+		if err != nil {
+			t.Fatalf("arena %d: %v", i, err)
+		}
+		doc := p.ParseString(md)
+		p.Close()
+		if html := doc.RenderHTML(OptDefault); html != want {
+			t.Errorf("RenderHTML = %q, want %q", html, want)
+		}
+		if xml := doc.RenderXML(OptDefault); xml == "" {
+			t.Error("RenderXML returned nothing")
+		}
+		var b bytes.Buffer
+		r := NewHTMLReader(doc, OptDefault)
+		if _, err := r.WriteTo(&b); err != nil {
+			t.Fatal(err)
+		}
+		doc.Close()
+		r.Close()
+		if b.String() != want {
+			t.Errorf("HTMLReader read %q, want %q", b.String(), want)
+		}
+		arena.Close()
+	}
+}
+
+// paragraphTexts returns the text of each paragraph under doc
+func paragraphTexts(doc Node) []string {
+	var texts []string
+	for c := doc.FirstChild(); c.node != nil; c = c.Next() {
+		texts = append(texts, c.TextContent())
+	}
+	return texts
+}
+
+func TestSwapWith(t *testing.T) {
+	tests := []struct {
+		a, b int
+		want string
+	}{
+		{0, 1, "b a c"},
+		{1, 0, "b a c"},
+		{1, 2, "a c b"},
+		{2, 1, "a c b"},
+		{0, 2, "c b a"},
+		{2, 0, "c b a"},
+	}
+	for _, tt := range tests {
+		p := NewParser(OptDefault)
+		doc := p.ParseString("a\n\nb\n\nc\n")
+		p.Close()
+		a, _ := doc.ChildAt(tt.a)
+		b, _ := doc.ChildAt(tt.b)
+		if err := a.SwapWith(b); err != nil {
+			t.Errorf("SwapWith(%d, %d): %v", tt.a, tt.b, err)
+		}
+		if got := strings.Join(paragraphTexts(doc), " "); got != tt.want {
+			t.Errorf("SwapWith(%d, %d) = %q, want %q", tt.a, tt.b, got, tt.want)
+		}
+		doc.Close()
+	}
+}
+
+func TestSplitAtHeadings(t *testing.T) {
+	p := NewParser(OptDefault)
+	doc := p.ParseString("intro\n\n## A\n\n```go\nx\n```\n\n## B\n\n- [link](/x \"t\")\n")
+	p.Close()
+	defer doc.Close()
+	sections, err := SplitAtHeadings(doc, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"<p>intro</p>\n",
+		"<h2>A</h2>\n<pre><code class=\"language-go\">x\n</code></pre>\n",
+		"<h2>B</h2>\n<ul>\n<li><a href=\"/x\" title=\"t\">link</a></li>\n</ul>\n",
+	}
+	if len(sections) != len(want) {
+		t.Fatalf("got %d sections, want %d", len(sections), len(want))
+	}
+	for i, s := range sections {
+		if html := s.RenderHTML(OptDefault); html != want[i] {
+			t.Errorf("section %d = %q, want %q", i, html, want[i])
+		}
+		s.Close()
+	}
+}
+
+func TestTextContent(t *testing.T) {
+	tests := []struct {
+		md, want string
+	}{
+		{"foo *bar* `baz`\n", "foo bar baz"},
+		{"foo\nbar\n", "foo bar"},
+		{"foo  \nbar\n", "foo bar"},
+		{"foo\n\nbar\n", "foo bar"},
+		{"- foo\n- bar\n\n> baz\n", "foo bar baz"},
+	}
+	for _, tt := range tests {
+		p := NewParser(OptDefault)
+		doc := p.ParseString(tt.md)
+		p.Close()
+		if got := doc.TextContent(); got != tt.want {
+			t.Errorf("TextContent(%q) = %q, want %q", tt.md, got, tt.want)
+		}
+		doc.Close()
+	}
+}
+
+func TestGenerateHeadingIDsMultiline(t *testing.T) {
+	p := NewParser(OptDefault)
+	doc := p.ParseString("foo\nbar\n===\n")
+	p.Close()
+	defer doc.Close()
+	ids := GenerateHeadingIDs(doc)
+	if id := ids[doc.FirstChild()]; id != "foo-bar" {
+		t.Errorf("heading id = %q, want %q", id, "foo-bar")
+	}
+}
+
+func TestCollectAllLiterals(t *testing.T) {
+	p := NewParser(OptDefault)
+	doc := p.ParseString("# Title\n\nSee [the docs](/docs \"Docs\") and ![logo](/logo.png).\n\n```go\nx := 1\n```\n\n<br>\n")
+	p.Close()
+	defer doc.Close()
+	want := []string{
+		"Title",
+		"See ", "/docs", "Docs", "the docs", " and ", "/logo.png", "logo", ".",
+		"go", "x := 1\n",
+		"<br>\n",
+	}
+	got := CollectAllLiterals(doc)
+	if len(got) != len(want) {
+		t.Fatalf("CollectAllLiterals returned %d strings, want %d: %q", len(got), len(want), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("CollectAllLiterals()[%d] = %q, want %q", i, got[i], want[i])
+		}
+	}
+}
+
+func TestParseBytesStream(t *testing.T) {
+	if _, err := ParseBytesStream(strings.NewReader("x"), MinChunkSize-1, OptDefault); err == nil {
+		t.Error("ParseBytesStream accepted a chunk size below MinChunkSize")
+	}
+	md := strings.Repeat("Some *text* split\nacross chunks\n\n", 100)
+	p := NewParser(OptDefault)
+	want := p.ParseString(md)
+	p.Close()
+	defer want.Close()
+	for _, size := range []int{MinChunkSize, 1000, len(md), 2 * len(md)} {
+		doc, err := ParseBytesStream(strings.NewReader(md), size, OptDefault)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !doc.Equal(want) {
+			t.Errorf("chunk size %d: document differs from ParseString", size)
+		}
+		doc.Close()
+	}
+}
+
+func TestSetHeadingText(t *testing.T) {
+	p := NewParser(OptDefault)
+	doc := p.ParseString("## A *complex* `heading` with [a link](/x)\n\nbody\n")
+	p.Close()
+	defer doc.Close()
+	h := doc.FirstChild()
+	if err := h.SetHeadingText("Plain"); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := doc.RenderHTML(OptDefault), "<h2>Plain</h2>\n<p>body</p>\n"; got != want {
+		t.Errorf("RenderHTML = %q, want %q", got, want)
+	}
+	if h.ChildCount() != 1 {
+		t.Errorf("heading has %d children, want 1", h.ChildCount())
+	}
+	para := h.Next()
+	if err := para.SetHeadingText("x"); err == nil {
+		t.Error("SetHeadingText succeeded on a paragraph")
+	}
+	if got := para.TextContent(); got != "body" {
+		t.Errorf("paragraph changed to %q", got)
+	}
+}
+
+func TestSmartPunctuationRewrite(t *testing.T) {
+	tests := []struct {
+		md, want string
+		count    int
+	}{
+		{"\"hello\" -- world --- end\n", "<p>“hello” – world — end</p>\n", 4},
+		{"don't 'quote'\n", "<p>don’t ‘quote’</p>\n", 3},
+		{"foo [\"bar\"] 'baz'\n", "<p>foo [“bar”] ‘baz’</p>\n", 4},
+		{"*\"emph\"* and \"[x](/y)\"\n", "<p><em>“emph”</em> and “<a href=\"/y\">x</a>”</p>\n", 4},
+		{"say\n\"hi\"\n", "<p>say\n“hi”</p>\n", 2},
+		{"code `\"x\" -- 'y'` stays\n", "<p>code <code>&quot;x&quot; -- 'y'</code> stays</p>\n", 0},
+		{"```\n\"block\" --\n```\n", "<pre><code>&quot;block&quot; --\n</code></pre>\n", 0},
+	}
+	for _, tt := range tests {
+		p := NewParser(OptDefault)
+		doc := p.ParseString(tt.md)
+		p.Close()
+		if count := SmartPunctuationRewrite(doc); count != tt.count {
+			t.Errorf("SmartPunctuationRewrite(%q) = %d, want %d", tt.md, count, tt.count)
+		}
+		if got := doc.RenderHTML(OptDefault); got != tt.want {
+			t.Errorf("%q rendered %q, want %q", tt.md, got, tt.want)
+		}
+		doc.Close()
+	}
+}
+
+// allTypesDocument returns a document with a node of every core type
+func allTypesDocument(t *testing.T) Node {
+	t.Helper()
+	p := NewParser(OptDefault)
+	doc := p.ParseString("# Heading\n\n> quote *emph* **strong** `code`\n> soft  \n> hard\n\n" +
+		"- [link](/url \"title\") ![image](/img.png)\n\n3) ordered <span>html</span>\n\n" +
+		"```go\ncode block\n```\n\n<div>\nhtml block\n</div>\n\n---\n")
+	p.Close()
+	block := NewNode(NodeCustomBlock)
+	inline := NewNode(NodeCustomInline)
+	if err := block.SetOnEnter("<section>"); err != nil {
+		t.Fatal(err)
+	}
+	block.SetOnExit("</section>")
+	inline.SetOnEnter("<kbd>")
+	inline.SetOnExit("</kbd>")
+	para := NewNode(NodeParagraph)
+	if err := para.AppendChild(inline); err != nil {
+		t.Fatal(err)
+	}
+	block.AppendChild(para)
+	if err := doc.AppendChild(block); err != nil {
+		t.Fatal(err)
+	}
+	return doc
+}
+
+func TestJSONRoundTrip(t *testing.T) {
+	doc := allTypesDocument(t)
+	defer doc.Close()
+	seen := map[NodeType]bool{}
+	Walk(doc, func(n Node, ev Event) error {
+		typ, _ := n.Type()
+		seen[typ] = true
+		return nil
+	})
+	for _, typ := range nodeTypes {
+		if !seen[typ] {
+			t.Errorf("test document has no %v node", typ)
+		}
+	}
+	data, err := json.Marshal(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var back Node
+	if err := json.Unmarshal(data, &back); err != nil {
+		t.Fatal(err)
+	}
+	defer back.Close()
+	if !back.Equal(doc) {
+		t.Errorf("round trip through %s changed the tree", data)
+	}
+	if got, want := back.RenderHTML(OptDefault), doc.RenderHTML(OptDefault); got != want {
+		t.Errorf("round trip renders %q, want %q", got, want)
+	}
+}
+
+func TestCustomBlockCanContainAnything(t *testing.T) {
+	doc := NewNode(NodeDocument)
+	defer doc.Close()
+	block := NewNode(NodeCustomBlock)
+	if err := doc.AppendChild(block); err != nil {
+		t.Fatal(err)
+	}
+	for _, typ := range []NodeType{NodeParagraph, NodeItem, NodeText, NodeEmph} {
+		if !block.CanContain(typ) {
+			t.Errorf("custom block cannot contain %v", typ)
+		}
+	}
+	if block.CanContain(NodeDocument) {
+		t.Error("custom block can contain a document")
+	}
+	text := NewNode(NodeText)
+	text.SetLiteral("inline in a block")
+	if err := block.AppendChild(text); err != nil {
+		t.Fatal(err)
+	}
+	if err := doc.Validate(); err != nil {
+		t.Errorf("Validate: %v", err)
+	}
+}
+
+func TestGoDataForgottenWhenMerged(t *testing.T) {
+	para := NewNode(NodeParagraph)
+	defer para.Close()
+	var texts []Node
+	for _, s := range []string{"a", "b", "c"} {
+		text := NewNode(NodeText)
+		text.SetLiteral(s)
+		text.SetGoData(s)
+		para.AppendChild(text)
+		texts = append(texts, text)
+	}
+	para.ConsolidateTextNodes()
+	if v := texts[0].GoData(); v != "a" {
+		t.Errorf("kept text node has Go data %v, want a", v)
+	}
+	for _, text := range texts[1:] {
+		if _, ok := goData.Load(uintptr(unsafe.Pointer(text.node))); ok {
+			t.Error("Go data of a merged text node was not removed")
+		}
+	}
+}
+
+func TestToASTAttributes(t *testing.T) {
+	p := NewParser(OptDefault)
+	doc := p.ParseString("## [a](/u \"t\")\n\n2. x\n")
+	p.Close()
+	defer doc.Close()
+	ast, err := doc.ToAST()
+	if err != nil {
+		t.Fatal(err)
+	}
+	heading, list := ast.Children[0], ast.Children[1]
+	link := heading.Children[0]
+	checks := []struct {
+		node *ASTNode
+		key  string
+		want string
+	}{
+		{heading, "level", "2"},
+		{link, "url", "/u"},
+		{link, "title", "t"},
+		{list, "list_type", "ordered"},
+		{list, "list_delim", "period"},
+		{list, "list_start", "2"},
+		{list, "tight", "true"},
+	}
+	for _, c := range checks {
+		if got := c.node.Attributes[c.key]; got != c.want {
+			t.Errorf("%s %s = %q, want %q", c.node.Type, c.key, got, c.want)
+		}
+	}
+}
+
+// optUnsafe is CMARK_OPT_UNSAFE of cmark 0.29 and later, earlier
+// versions ignore it
+const optUnsafe Opt = 1 << 17
+
+func TestRenderHTMLSafeClearsUnsafe(t *testing.T) {
+	p := NewParser(OptDefault)
+	doc := p.ParseString("<script>alert(1)</script>\n\n[x](javascript:alert(1)) <b>y</b>\n")
+	p.Close()
+	defer doc.Close()
+	html := doc.RenderHTMLSafe(optUnsafe)
+	for _, bad := range []string{"<script>", "javascript:", "<b>"} {
+		if strings.Contains(html, bad) {
+			t.Errorf("RenderHTMLSafe output contains %q:\n%s", bad, html)
+		}
+	}
+}
+
+// sliceAllocator is not comparable, it has a slice field
+type sliceAllocator struct {
+	sizes []int
+}
+
+func (a sliceAllocator) Alloc(size int) unsafe.Pointer                    { return nil }
+func (a sliceAllocator) Realloc(ptr unsafe.Pointer, n int) unsafe.Pointer { return nil }
+func (a sliceAllocator) Free(ptr unsafe.Pointer)                          {}
+
+func TestAllocatorSlots(t *testing.T) {
+	if _, err := NewParserWithAllocator(OptDefault, sliceAllocator{}); err == nil {
+		t.Error("NewParserWithAllocator accepted a non-comparable allocator")
+	}
+	// more allocators than slots, each Close or ReleaseAllocator frees
+	// its slot
+	for i := 0; i < 2*memSlots; i++ {
+		arena := NewArenaAllocator(1 << 12)
+		mem, err := NewMem(arena)
+		if err != nil {
+			t.Fatalf("NewMem %d: %v", i, err)
+		}
+		p := NewParserWithMem(OptDefault, mem)
+		p.ParseString("x\n").Close()
+		p.Close()
+		mem.Close()
+
+		p, err = NewParserWithAllocator(OptDefault, arena)
+		if err != nil {
+			t.Fatalf("NewParserWithAllocator %d: %v", i, err)
+		}
+		p.ParseString("x\n").Close()
+		p.Close()
+		ReleaseAllocator(arena)
+		arena.Close()
+	}
+}
+
+func TestParseChecked(t *testing.T) {
+	p := NewParser(OptDefault)
+	defer p.Close()
+	if err := p.SetMaxLineLength(4); err != nil {
+		t.Fatal(err)
+	}
+	if doc, err := p.ParseStringChecked("too long\n"); err != ErrLineTooLong || !doc.IsNil() {
+		t.Errorf("ParseStringChecked = %v, %v, want no document and ErrLineTooLong", doc, err)
+	}
+	if err := p.SetMaxInputSize(-1); err == nil {
+		t.Error("SetMaxInputSize accepted a negative size")
+	}
+	if err := p.SetMaxInputSize(3); err != nil {
+		t.Fatal(err)
+	}
+	doc, err := p.ParseBytesChecked([]byte("ok\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer doc.Close()
+	if html := doc.RenderHTML(OptDefault); html != "<p>ok</p>\n" {
+		t.Errorf("RenderHTML = %q", html)
+	}
+}
+
+func TestMigrateHTMLBreaks(t *testing.T) {
+	p := NewParser(OptDefault)
+	doc := p.ParseString("a<br>b\n\n<hr />\n\n<div>x</div>\n\n    code\n")
+	p.Close()
+	defer doc.Close()
+	if _, err := migrateHTMLBreaks(doc); err != nil {
+		t.Fatal(err)
+	}
+	const want = "<p>a<br />\nb</p>\n<hr />\n<div>x</div>\n<pre><code>code\n</code></pre>\n"
+	if html := doc.RenderHTML(optUnsafe); html != want {
+		t.Errorf("migrated html = %q, want %q", html, want)
+	}
+	if err := doc.MigrateToGFM(); err != nil {
+		t.Errorf("MigrateToGFM: %v", err)
+	}
+	if code := doc.LastChild(); code.FenceInfo() != "" {
+		t.Errorf("MigrateToGFM set info %q on an indented code block", code.FenceInfo())
+	}
+}
+
+func TestParserPoolPutDiscardsInput(t *testing.T) {
+	pool := NewParserPool(OptDefault, 1)
+	p := pool.Get()
+	p.WriteString("left over\n")
+	pool.Put(p)
+	doc := pool.ParseBytes([]byte("x\n"))
+	defer doc.Close()
+	if html := doc.RenderHTML(OptDefault); html != "<p>x</p>\n" {
+		t.Errorf("RenderHTML = %q, want only the new document", html)
+	}
+}
+
+func TestDiffRoundTrip(t *testing.T) {
+	pairs := [][2]string{
+		{"a\n\nb\n", "a\n\nc\n\nb\n"},
+		{"# T\n\n- x\n- y\n", "## T\n\n- x\n- z\n- y\n"},
+		{"*a* b\n", "**a** b\n"},
+		{"1. a\n", "> 1. a\n\ntail\n"},
+		{"", "x\n"},
+		{"x\n\ny\n", ""},
+	}
+	p := NewParser(OptDefault)
+	defer p.Close()
+	for _, pair := range pairs {
+		a, b := p.ParseString(pair[0]), p.ParseString(pair[1])
+		if err := a.ApplyDiff(Diff(a, b)); err != nil {
+			t.Errorf("ApplyDiff(%q -> %q): %v", pair[0], pair[1], err)
+		} else if !a.Equal(b) {
+			t.Errorf("ApplyDiff(%q -> %q) gave %q", pair[0], pair[1], a.RenderCommonMark(OptDefault, 0))
+		}
+		a.Close()
+		b.Close()
+	}
+}
+
+func TestDiffRootChanged(t *testing.T) {
+	a, b := NewNode(NodeParagraph), NewNode(NodeHeading)
+	defer a.Close()
+	defer b.Close()
+	if err := a.ApplyDiff(Diff(a, b)); err != ErrDiffRootChanged {
+		t.Errorf("ApplyDiff of roots of different types = %v, want ErrDiffRootChanged", err)
+	}
+}
@@ -0,0 +1,34 @@
+package cmark
+
+// CollectAllLiterals returns the non-empty strings of every node under
+// root (including root) in document order: literals, code block fence
+// info, and link and image URLs and titles
+//
+// Useful for quick debugging snapshots of a tree's text content
+func CollectAllLiterals(root Node) []string {
+	var lits []string
+	add := func(s string) {
+		if s != "" {
+			lits = append(lits, s)
+		}
+	}
+	iter := root.Iter()
+	defer iter.Close()
+	for ev := iter.Next(); ev != EventDone; ev = iter.Next() {
+		if ev != EventEnter {
+			continue
+		}
+		n := iter.Node()
+		switch typ, _ := n.Type(); typ {
+		case NodeCodeBlock:
+			add(n.FenceInfo())
+			add(n.Literal())
+		case NodeLink, NodeImage:
+			add(n.URL())
+			add(n.Title())
+		default:
+			add(n.Literal())
+		}
+	}
+	return lits
+}
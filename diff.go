@@ -0,0 +1,201 @@
+package cmark
+
+import "fmt"
+
+// DiffKind is the kind of change a DiffOp describes
+type DiffKind int
+
+const (
+	// DiffAdded means Node was inserted at Path
+	DiffAdded DiffKind = iota
+	// DiffRemoved means the node at Path was removed
+	DiffRemoved
+	// DiffModified means the node at Path now has the type, literal and
+	// attributes of Node, its children are described by other ops
+	DiffModified
+)
+
+func (k DiffKind) String() string {
+	switch k {
+	case DiffAdded:
+		return "added"
+	case DiffRemoved:
+		return "removed"
+	case DiffModified:
+		return "modified"
+	}
+	return "<unknown>"
+}
+
+// DiffOp is a single change between two trees
+//
+// Path holds the child indexes leading from the root to the changed
+// node, it is relative to the tree as it is after all earlier ops
+// have been applied
+// Node is the added or modified node from the new tree, or the
+// removed node from the old tree
+type DiffOp struct {
+	Kind DiffKind
+	Path []int
+	Node Node
+}
+
+// Diff returns the ops which turn the tree a into the tree b
+// Children are matched with a longest common subsequence of equal
+// subtrees, unmatched children of the same type and position are
+// diffed recursively, others are removed and added
+//
+// The ops refer to nodes of a and b, which must not be closed while
+// the ops are in use
+//
+// If a and b have different types the ops remove a and add b at the
+// root, with an empty Path, which ApplyDiff cannot apply and rejects
+// with ErrDiffRootChanged
+func Diff(a, b Node) []DiffOp {
+	if ta, _ := a.Type(); ta != typeOf(b) {
+		return []DiffOp{{Kind: DiffRemoved, Node: a}, {Kind: DiffAdded, Node: b}}
+	}
+	return diffNodes(nil, a, b, nil)
+}
+
+// typeOf returns the type of n, NodeNone if it has none
+func typeOf(n Node) NodeType {
+	typ, _ := n.Type()
+	return typ
+}
+
+// childNodes returns the children of n in order
+func childNodes(n Node) []Node {
+	var c []Node
+	for ch := n.FirstChild(); ch.node != nil; ch = ch.Next() {
+		c = append(c, ch)
+	}
+	return c
+}
+
+// diffNodes appends the ops turning a into b, which have the same type
+func diffNodes(ops []DiffOp, a, b Node, path []int) []DiffOp {
+	if attributesOf(a) != attributesOf(b) {
+		ops = append(ops, DiffOp{Kind: DiffModified, Path: path, Node: b})
+	}
+	ca, cb := childNodes(a), childNodes(b)
+	// lcs[i][j] is the length of the longest common subsequence
+	// of ca[i:] and cb[j:]
+	lcs := make([][]int, len(ca)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(cb)+1)
+	}
+	for i := len(ca) - 1; i >= 0; i-- {
+		for j := len(cb) - 1; j >= 0; j-- {
+			if ca[i].Equal(cb[j]) {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+	childPath := func(k int) []int {
+		p := make([]int, len(path)+1)
+		copy(p, path)
+		p[len(path)] = k
+		return p
+	}
+	// k is the index in the partly patched tree
+	i, j, k := 0, 0, 0
+	for i < len(ca) || j < len(cb) {
+		switch {
+		case i < len(ca) && j < len(cb) && ca[i].Equal(cb[j]):
+			i, j, k = i+1, j+1, k+1
+		case i < len(ca) && j < len(cb) && lcs[i+1][j+1] == lcs[i][j] &&
+			typeOf(ca[i]) == typeOf(cb[j]):
+			// both are unmatched, diff them in place
+			ops = diffNodes(ops, ca[i], cb[j], childPath(k))
+			i, j, k = i+1, j+1, k+1
+		case j == len(cb) || (i < len(ca) && lcs[i+1][j] >= lcs[i][j+1]):
+			ops = append(ops, DiffOp{Kind: DiffRemoved, Path: childPath(k), Node: ca[i]})
+			i++
+		default:
+			ops = append(ops, DiffOp{Kind: DiffAdded, Path: childPath(k), Node: cb[j]})
+			j, k = j+1, k+1
+		}
+	}
+	return ops
+}
+
+// nodeAt returns the node at path below n
+func (n Node) nodeAt(path []int) (Node, error) {
+	for _, i := range path {
+		c, err := n.ChildAt(i)
+		if err != nil {
+			return Node{}, n.nodeError("ApplyDiff", fmt.Sprintf("path %v does not exist", path))
+		}
+		n = c
+	}
+	return n, nil
+}
+
+// ApplyDiff applies ops, as returned by Diff, to the tree of this node
+// in order, added nodes are copies of the op's node
+// An error is returned if a path does not exist, ops before it have
+// already been applied, and ErrDiffRootChanged for an op removing or
+// adding the root
+func (n Node) ApplyDiff(ops []DiffOp) error {
+	for _, op := range ops {
+		switch op.Kind {
+		case DiffAdded:
+			if len(op.Path) == 0 {
+				return ErrDiffRootChanged
+			}
+			last := len(op.Path) - 1
+			parent, err := n.nodeAt(op.Path[:last])
+			if err != nil {
+				return err
+			}
+			// the index may be one past the last child, to append
+			next, err := parent.nodeAt(op.Path[last:])
+			if err != nil && op.Path[last] != parent.ChildCount() {
+				return err
+			}
+			clone, err := op.Node.Clone()
+			if err != nil {
+				return err
+			}
+			if next.node != nil {
+				err = next.InsertBefore(clone)
+			} else {
+				err = parent.AppendChild(clone)
+			}
+			if err != nil {
+				clone.Close()
+				return err
+			}
+		case DiffRemoved:
+			if len(op.Path) == 0 {
+				return ErrDiffRootChanged
+			}
+			c, err := n.nodeAt(op.Path)
+			if err != nil {
+				return err
+			}
+			c.Unlink()
+			c.Close()
+		case DiffModified:
+			c, err := n.nodeAt(op.Path)
+			if err != nil {
+				return err
+			}
+			typ := typeOf(op.Node)
+			if typeOf(c) != typ {
+				return c.nodeError("ApplyDiff", "cannot change the type of a node")
+			}
+			if err := c.setJSONAttributes(typ, jsonNode{nodeAttributes: attributesOf(op.Node)}); err != nil {
+				return err
+			}
+		default:
+			return n.nodeError("ApplyDiff", "unknown diff kind "+op.Kind.String())
+		}
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package cmark
+
+import (
+	"errors"
+	"fmt"
+)
+
+// SourceRange is the span of the source a node was parsed from,
+// lines and columns start at 1 and are 0 when unknown
+type SourceRange struct {
+	StartLine, StartColumn int
+	EndLine, EndColumn     int
+}
+
+// NodeError is returned when an operation on a node fails
+type NodeError struct {
+	// Op is the failed method, e.g. "AppendChild"
+	Op string
+	// Range is the source range of the node the method was called on
+	Range SourceRange
+	Msg   string
+}
+
+func (e *NodeError) Error() string {
+	if e.Range.StartLine == 0 {
+		return e.Op + ": " + e.Msg
+	}
+	return fmt.Sprintf("%s: %s (line %d, column %d)", e.Op, e.Msg, e.Range.StartLine, e.Range.StartColumn)
+}
+
+// nodeError returns a NodeError for an operation on n
+func (n Node) nodeError(op, msg string) error {
+	return &NodeError{Op: op, Range: n.Range(), Msg: msg}
+}
+
+// ErrInvalidHeadingLevel is returned when setting a heading level
+// outside the range 1 to 6
+var ErrInvalidHeadingLevel = errors.New("Heading level must be from 1 to 6")
+
+// ErrCGONotAvailable is returned by every operation when the package is
+// built without cgo, in which case cmark cannot be called
+var ErrCGONotAvailable = errors.New("cmark needs cgo, which is not enabled")
+
+// ErrLineTooLong is returned by Write and ParseBytesChecked when a line
+// is longer than the parser's SetMaxLineLength
+var ErrLineTooLong = errors.New("Line is longer than the maximum line length")
+
+// ErrInputTooLarge is returned by Write and ParseBytesChecked when the
+// document would be larger than the parser's SetMaxInputSize
+var ErrInputTooLarge = errors.New("Input is larger than the maximum input size")
+
+// ErrEditRange is returned by IncrementalParser.Edit for a range which
+// is not within the source
+var ErrEditRange = errors.New("Edit range is not within the source")
+
+// ErrDiffRootChanged is returned by ApplyDiff for ops which remove or
+// add the root node, as Diff returns for roots of different types
+// A node cannot replace the root it is called on, replace the whole
+// tree instead
+var ErrDiffRootChanged = errors.New("Diff replaces the root node")
@@ -0,0 +1,28 @@
+//go:build cgo
+
+package cmark
+
+import (
+	"strings"
+	"testing"
+)
+
+func FuzzParse(f *testing.F) {
+	f.Add([]byte(""))
+	f.Add([]byte(strings.Repeat("long line ", 10000) + "\n"))
+	f.Add([]byte(strings.Repeat("> ", 1000) + "deep\n"))
+	f.Add([]byte(strings.Repeat("- ", 500) + "item\n"))
+	f.Add([]byte("<div onclick=\"x()\">\n<script>alert(1)</script>\n</div>\n\n<em>inline</em> html\n"))
+	f.Add([]byte("# h\n\n[a](<javascript:x> \"t\") ![i](/i.png)\n\n```go\ncode\n```\n"))
+	f.Add([]byte("[ref]\n\n[ref]: /url\n*a **b* c**\n"))
+	f.Fuzz(func(t *testing.T, b []byte) {
+		p := NewParser(OptDefault)
+		defer p.Close()
+		doc := p.ParseBytes(b)
+		if doc.node == nil {
+			t.Fatal("ParseBytes returned no document")
+		}
+		doc.RenderHTML(OptDefault)
+		doc.Close()
+	})
+}
@@ -0,0 +1,519 @@
+//go:build cgo && cmark_gfm
+
+package cmark
+
+// #cgo pkg-config: libcmark-gfm
+// #cgo LDFLAGS: -lcmark-gfm-extensions
+// #cgo CFLAGS: -DGO_CMARK_GFM
+// #include <stdlib.h>
+// #include "cmark_go.h"
+// #include <cmark-gfm-extension_api.h>
+// #include <cmark-gfm-core-extensions.h>
+//
+// // defined by the table extension but not in an installed header
+// extern cmark_node_type CMARK_NODE_TABLE;
+// extern cmark_node_type CMARK_NODE_TABLE_ROW;
+// extern cmark_node_type CMARK_NODE_TABLE_CELL;
+// extern cmark_node_type CMARK_NODE_STRIKETHROUGH;
+import "C"
+import (
+	"errors"
+	"sync"
+	"unsafe"
+)
+
+// Node types added by the table extension,
+// these are assigned by cmark-gfm at startup and so are not constants
+var (
+	NodeTable     NodeType
+	NodeTableRow  NodeType
+	NodeTableCell NodeType
+)
+
+// NodeStrikethrough is the node type added by the strikethrough extension
+var NodeStrikethrough NodeType
+
+func init() {
+	C.cmark_gfm_core_extensions_ensure_registered()
+	NodeTable = NodeType(C.CMARK_NODE_TABLE)
+	NodeTableRow = NodeType(C.CMARK_NODE_TABLE_ROW)
+	NodeTableCell = NodeType(C.CMARK_NODE_TABLE_CELL)
+	NodeStrikethrough = NodeType(C.CMARK_NODE_STRIKETHROUGH)
+}
+
+// extensionNodeTypes returns the node types of the core extensions
+func extensionNodeTypes() []NodeType {
+	return []NodeType{
+		NodeTable, NodeTableRow, NodeTableCell, NodeStrikethrough,
+		NodeFootnoteDefinition, NodeFootnoteReference,
+	}
+}
+
+// isExtensionNodeType returns true for the node types of the
+// core extensions
+func isExtensionNodeType(t NodeType) bool {
+	for _, typ := range extensionNodeTypes() {
+		if t == typ {
+			return true
+		}
+	}
+	return false
+}
+
+var (
+	typeNamesOnce sync.Once
+	// typeNames maps the extension node types to their names
+	typeNames map[NodeType]string
+)
+
+// extensionTypeName returns the name cmark-gfm gives the node type t
+// of a core extension, which it only knows for nodes with the
+// extension set
+func extensionTypeName(t NodeType) string {
+	if !isExtensionNodeType(t) {
+		return "<unknown>"
+	}
+	typeNamesOnce.Do(func() {
+		typeNames = map[NodeType]string{}
+		for _, typ := range extensionNodeTypes() {
+			n := newNodeFor(typ, nodeAttributes{})
+			typeNames[typ] = C.GoString(C.cmark_node_get_type_string(n.node))
+			n.Close()
+		}
+	})
+	return typeNames[t]
+}
+
+// isBlockType and isInlineType use the kind cmark-gfm keeps in the
+// high bits of a node type, which extension types have too
+func isBlockType(t NodeType) bool {
+	return t&C.CMARK_NODE_TYPE_MASK == C.CMARK_NODE_TYPE_BLOCK
+}
+
+func isInlineType(t NodeType) bool {
+	return t&C.CMARK_NODE_TYPE_MASK == C.CMARK_NODE_TYPE_INLINE
+}
+
+// extensionCanContain follows the rules of the core extensions for
+// which children their node types may have, ok is false if parent is
+// not one of their types
+func extensionCanContain(parent, child NodeType) (can, ok bool) {
+	switch parent {
+	case NodeTable:
+		return child == NodeTableRow, true
+	case NodeTableRow:
+		return child == NodeTableCell, true
+	case NodeTableCell:
+		switch child {
+		case NodeText, NodeCode, NodeHTMLInline, NodeEmph, NodeStrong,
+			NodeLink, NodeImage, NodeStrikethrough, NodeFootnoteReference:
+			return true, true
+		}
+		return false, true
+	case NodeStrikethrough:
+		return isInlineType(child), true
+	case NodeFootnoteDefinition:
+		return isBlockType(child) && child != NodeItem, true
+	}
+	return false, false
+}
+
+// newNodeLike returns a new node of type typ belonging to the same
+// syntax extension as n, which extension nodes need to be rendered
+// and to accept children
+func newNodeLike(n Node, typ NodeType) Node {
+	ext := C.cmark_node_get_syntax_extension(n.node)
+	if ext == nil {
+		return NewNode(typ)
+	}
+	return Node{node: C.cmark_node_new_with_ext(C.cmark_node_type(typ), ext)}
+}
+
+// newNodeFor returns a new node of type typ belonging to the syntax
+// extension its type, or for a task list item a.Task, needs
+func newNodeFor(typ NodeType, a nodeAttributes) Node {
+	var name string
+	switch {
+	case typ == NodeTable || typ == NodeTableRow || typ == NodeTableCell:
+		name = "table"
+	case typ == NodeStrikethrough:
+		name = "strikethrough"
+	case typ == NodeItem && a.Task:
+		name = "tasklist"
+	default:
+		return NewNode(typ)
+	}
+	ext, err := FindExtension(name)
+	if err != nil {
+		return NewNode(typ)
+	}
+	return Node{node: C.cmark_node_new_with_ext(C.cmark_node_type(typ), ext.ext)}
+}
+
+// copyExtensionAttributes copies what cmark-gfm records for a node
+// beyond its attributes, the fence of a code block
+func copyExtensionAttributes(dst, src Node, typ NodeType) error {
+	if typ == NodeCodeBlock {
+		var length, offset C.int
+		var char C.char
+		if fenced := C.cmark_node_get_fenced(src.node, &length, &offset, &char); fenced != 0 {
+			C.cmark_node_set_fenced(dst.node, fenced, length, offset, char)
+		}
+	}
+	return nil
+}
+
+// extensionAttributes sets the attributes the core extensions keep
+// for n in a
+func extensionAttributes(n Node, typ NodeType, a *nodeAttributes) {
+	switch typ {
+	case NodeItem:
+		if C.GoString(C.cmark_node_get_type_string(n.node)) == "tasklist" {
+			a.Task = true
+			a.Checked = n.TaskListChecked()
+		}
+	case NodeTable:
+		aligns := make([]byte, n.TableColumns())
+		for i := range aligns {
+			aligns[i] = byte(n.TableCellAlignment(i))
+			if aligns[i] == byte(AlignNone) {
+				aligns[i] = 'n'
+			}
+		}
+		a.Alignments = string(aligns)
+	case NodeTableRow:
+		a.Header = C.cmark_gfm_extensions_get_table_row_is_header(n.node) != 0
+	case NodeFootnoteDefinition, NodeFootnoteReference:
+		a.Label = n.FootnoteLabel()
+	}
+}
+
+// setExtensionAttributes sets the attributes of the core extensions
+// in a on n
+func setExtensionAttributes(n Node, typ NodeType, a nodeAttributes) error {
+	switch typ {
+	case NodeItem:
+		if a.Task {
+			return n.SetTaskListChecked(a.Checked)
+		}
+	case NodeTable:
+		cols := len(a.Alignments)
+		if C.cmark_gfm_extensions_set_table_columns(n.node, C.uint16_t(cols)) == 0 {
+			return n.nodeError("SetTableColumns", "table columns could not be set")
+		}
+		if cols == 0 {
+			return nil
+		}
+		aligns := []byte(a.Alignments)
+		for i, al := range aligns {
+			if al == 'n' {
+				aligns[i] = byte(AlignNone)
+			}
+		}
+		calign := C.CBytes(aligns)
+		defer C.free(calign)
+		if C.cmark_gfm_extensions_set_table_alignments(n.node, C.uint16_t(cols), (*C.uint8_t)(calign)) == 0 {
+			return n.nodeError("SetTableAlignments", "table alignments could not be set")
+		}
+	case NodeTableRow:
+		header := C.int(0)
+		if a.Header {
+			header = 1
+		}
+		C.cmark_gfm_extensions_set_table_row_is_header(n.node, header)
+	case NodeFootnoteDefinition, NodeFootnoteReference:
+		return n.SetFootnoteLabel(a.Label)
+	}
+	return nil
+}
+
+var (
+	extMu sync.Mutex
+	// extensions are attached to every new parser
+	// and passed to the html renderer
+	extensions *C.cmark_llist
+	// footnotes are a parser option in cmark-gfm rather than an extension
+	footnotes bool
+)
+
+// SyntaxExtension is a cmark-gfm syntax extension such as "table"
+type SyntaxExtension struct {
+	ext *C.cmark_syntax_extension
+}
+
+// FindExtension looks up a cmark-gfm syntax extension by name
+func FindExtension(name string) (SyntaxExtension, error) {
+	cname := C.CString(name)
+	defer C.free(unsafe.Pointer(cname))
+	ext := C.cmark_find_syntax_extension(cname)
+	if ext == nil {
+		return SyntaxExtension{}, errors.New("Extension " + name + " could not be found")
+	}
+	return SyntaxExtension{ext: ext}, nil
+}
+
+// AttachExtension enables a syntax extension for this parser only,
+// call it before writing to the parser
+func (p Parser) AttachExtension(ext SyntaxExtension) error {
+	if C.cmark_parser_attach_syntax_extension(p.parser, ext.ext) == 0 {
+		return errors.New("AttachExtension failed")
+	}
+	return nil
+}
+
+// ExtendedRenderHTML renders html from the document using the given
+// extensions rather than the registered ones
+func ExtendedRenderHTML(node Node, opts Opt, extensions []SyntaxExtension) string {
+	mem := C.cmark_get_default_mem_allocator()
+	var list *C.cmark_llist
+	for _, ext := range extensions {
+		list = C.cmark_llist_append(mem, list, unsafe.Pointer(ext.ext))
+	}
+	html := C.cmark_render_html(node.node, C.int(opts), list)
+	C.cmark_llist_free(mem, list)
+	gstr := C.GoString(html)
+	freeRendered(node.node, html)
+	return gstr
+}
+
+// registerExtension adds the named cmark-gfm extension to the
+// extensions used by new parsers
+func registerExtension(name string) error {
+	ext, err := FindExtension(name)
+	if err != nil {
+		return err
+	}
+	extMu.Lock()
+	defer extMu.Unlock()
+	for l := extensions; l != nil; l = l.next {
+		if l.data == unsafe.Pointer(ext.ext) {
+			return nil
+		}
+	}
+	extensions = C.cmark_llist_append(C.cmark_get_default_mem_allocator(), extensions, unsafe.Pointer(ext.ext))
+	return nil
+}
+
+func newParser(options Opt) *C.cmark_parser {
+	return newParserWithMem(options, C.cmark_get_default_mem_allocator())
+}
+
+func newParserWithMem(options Opt, mem *C.cmark_mem) *C.cmark_parser {
+	extMu.Lock()
+	defer extMu.Unlock()
+	if footnotes {
+		options |= C.CMARK_OPT_FOOTNOTES
+	}
+	p := C.cmark_parser_new_with_mem(C.int(options), mem)
+	for l := extensions; l != nil; l = l.next {
+		C.cmark_parser_attach_syntax_extension(p, (*C.cmark_syntax_extension)(l.data))
+	}
+	return p
+}
+
+// renderHTML renders with a copy of the registered extensions, so that
+// extMu is not held while rendering
+func renderHTML(node *C.cmark_node, options C.int) *C.char {
+	mem := C.cmark_get_default_mem_allocator()
+	var list *C.cmark_llist
+	extMu.Lock()
+	for l := extensions; l != nil; l = l.next {
+		list = C.cmark_llist_append(mem, list, l.data)
+	}
+	extMu.Unlock()
+	html := C.cmark_render_html(node, options, list)
+	C.cmark_llist_free(mem, list)
+	return html
+}
+
+// markdownToHTML parses with the registered extensions,
+// which cmark_markdown_to_html would not use
+func markdownToHTML(text *C.char, size C.size_t, options Opt) *C.char {
+	p := newParser(options)
+	C.cmark_parser_feed(p, text, size)
+	doc := C.cmark_parser_finish(p)
+	C.cmark_parser_free(p)
+	html := renderHTML(doc, C.int(options))
+	C.cmark_node_free(doc)
+	return html
+}
+
+// fenceLengthUnsupported is the SetFenceLength error message
+const fenceLengthUnsupported = "node is not a fenced code block"
+
+func fenceLength(node *C.cmark_node) int {
+	var length, offset C.int
+	var char C.char
+	if C.cmark_node_get_fenced(node, &length, &offset, &char) == 0 {
+		return -1
+	}
+	return int(length)
+}
+
+func setFenceLength(node *C.cmark_node, length int) bool {
+	var old, offset C.int
+	var char C.char
+	if C.cmark_node_get_fenced(node, &old, &offset, &char) == 0 {
+		return false
+	}
+	return C.cmark_node_set_fenced(node, 1, C.int(length), offset, char) != 0
+}
+
+// RegisterTableExtension enables GFM tables for parsers created afterwards
+func RegisterTableExtension() error {
+	return registerExtension("table")
+}
+
+// TableAlignment is the alignment of a table column
+type TableAlignment byte
+
+const (
+	AlignNone   TableAlignment = 0
+	AlignLeft   TableAlignment = 'l'
+	AlignCenter TableAlignment = 'c'
+	AlignRight  TableAlignment = 'r'
+)
+
+// TableColumns returns the number of columns of a table node
+func (n Node) TableColumns() int {
+	return int(C.cmark_gfm_extensions_get_table_columns(n.node))
+}
+
+// TableCellAlignment returns the alignment of column col of a table node
+// or AlignNone if col is out of range
+func (n Node) TableCellAlignment(col int) TableAlignment {
+	cols := n.TableColumns()
+	aligns := C.cmark_gfm_extensions_get_table_alignments(n.node)
+	if col < 0 || col >= cols || aligns == nil {
+		return AlignNone
+	}
+	return TableAlignment(unsafe.Slice(aligns, cols)[col])
+}
+
+// RegisterStrikethroughExtension enables GFM ~~strikethrough~~
+// for parsers created afterwards
+func RegisterStrikethroughExtension() error {
+	return registerExtension("strikethrough")
+}
+
+// RegisterAutolinkExtension enables GFM autolinks for parsers created
+// afterwards, bare URLs (www.example.com, https://example.com) and email
+// addresses become NodeLink nodes
+//
+// Autolinks are ordinary links so safe rendering filters their URLs like any
+// other link, and OptSmart punctuation is not applied to the link text
+func RegisterAutolinkExtension() error {
+	return registerExtension("autolink")
+}
+
+// RegisterTasklistExtension enables GFM task list items ("- [x] done")
+// for parsers created afterwards
+func RegisterTasklistExtension() error {
+	return registerExtension("tasklist")
+}
+
+// TaskListChecked returns true if the node is a checked task list item
+func (n Node) TaskListChecked() bool {
+	return bool(C.cmark_gfm_extensions_get_tasklist_item_checked(n.node))
+}
+
+// SetTaskListChecked checks or unchecks a task list item
+func (n Node) SetTaskListChecked(checked bool) error {
+	if C.cmark_gfm_extensions_set_tasklist_item_checked(n.node, C.bool(checked)) == 0 {
+		return errors.New("SetTaskListChecked failed")
+	}
+	return nil
+}
+
+// Node types used for footnotes
+const (
+	NodeFootnoteDefinition NodeType = C.CMARK_NODE_FOOTNOTE_DEFINITION
+	NodeFootnoteReference  NodeType = C.CMARK_NODE_FOOTNOTE_REFERENCE
+)
+
+// RegisterFootnoteExtension enables footnotes ("[^1]") for parsers
+// created afterwards
+func RegisterFootnoteExtension() error {
+	extMu.Lock()
+	defer extMu.Unlock()
+	footnotes = true
+	return nil
+}
+
+// FootnoteLabel returns the label of a footnote node,
+// e.g. "1" for [^1]
+func (n Node) FootnoteLabel() string {
+	return C.GoString(C.cmark_node_get_literal(n.node))
+}
+
+func (n Node) SetFootnoteLabel(label string) error {
+	clabel := C.CString(label)
+	defer C.free(unsafe.Pointer(clabel))
+	if C.cmark_node_set_literal(n.node, clabel) == 0 {
+		return errors.New("SetFootnoteLabel failed")
+	}
+	return nil
+}
+
+// RenderPlainText renders plain text from the document
+// wrapWidth is the wrap width (0 indicates no wrapping)
+//
+// Only cmark-gfm provides a plain text renderer so this method is
+// only available when building with the cmark_gfm tag
+func (n Node) RenderPlainText(options Opt, wrapWidth int) string {
+	text := C.cmark_render_plaintext(n.node, C.int(options), C.int(wrapWidth))
+	gstr := C.GoString(text)
+	freeRendered(n.node, text)
+	return gstr
+}
+
+// gfmExtensions are the extensions github.com enables
+var gfmExtensions = []string{"table", "strikethrough", "autolink", "tagfilter", "tasklist"}
+
+func findGFMExtensions() ([]SyntaxExtension, error) {
+	exts := make([]SyntaxExtension, len(gfmExtensions))
+	for i, name := range gfmExtensions {
+		ext, err := FindExtension(name)
+		if err != nil {
+			return nil, err
+		}
+		exts[i] = ext
+	}
+	return exts, nil
+}
+
+// NewGFMParser builds a parser with all GitHub Flavored Markdown
+// extensions and footnotes enabled, whether or not they are registered
+// The extensions must also be registered, or passed to
+// ExtendedRenderHTML, for tables and the tag filter to be rendered
+func NewGFMParser(opts Opt) (Parser, error) {
+	exts, err := findGFMExtensions()
+	if err != nil {
+		return Parser{}, err
+	}
+	p := NewParser(opts | C.CMARK_OPT_FOOTNOTES)
+	for _, ext := range exts {
+		if err := p.AttachExtension(ext); err != nil {
+			p.Close()
+			return Parser{}, err
+		}
+	}
+	return p, nil
+}
+
+// RenderGFMHTML renders html from GitHub Flavored Markdown, with all
+// its extensions and footnotes enabled
+func RenderGFMHTML(markdown []byte) (string, error) {
+	exts, err := findGFMExtensions()
+	if err != nil {
+		return "", err
+	}
+	p, err := NewGFMParser(OptDefault)
+	if err != nil {
+		return "", err
+	}
+	doc := p.ParseBytes(markdown)
+	p.Close()
+	defer doc.Close()
+	return ExtendedRenderHTML(doc, C.CMARK_OPT_FOOTNOTES, exts), nil
+}
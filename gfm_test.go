@@ -0,0 +1,123 @@
+//go:build cgo && cmark_gfm
+
+package cmark
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+const gfmTable = "| a | b |\n|---|:-:|\n| 1 | ~~2~~ |\n"
+
+func parseGFM(t *testing.T, md string) Node {
+	t.Helper()
+	if err := RegisterTableExtension(); err != nil {
+		t.Fatal(err)
+	}
+	if err := RegisterStrikethroughExtension(); err != nil {
+		t.Fatal(err)
+	}
+	p := NewParser(OptDefault)
+	defer p.Close()
+	return p.ParseString(md)
+}
+
+func TestGFMNodeKinds(t *testing.T) {
+	doc := parseGFM(t, gfmTable)
+	defer doc.Close()
+	kinds := map[NodeType]string{
+		NodeTable:         "block",
+		NodeTableRow:      "block",
+		NodeTableCell:     "block",
+		NodeStrikethrough: "inline",
+	}
+	seen := map[NodeType]bool{}
+	Walk(doc, func(n Node, ev Event) error {
+		typ, _ := n.Type()
+		kind, ok := kinds[typ]
+		if !ok || ev != EventEnter {
+			return nil
+		}
+		seen[typ] = true
+		if n.IsBlock() != (kind == "block") || n.IsInline() != (kind == "inline") {
+			t.Errorf("%v: IsBlock %v, IsInline %v, want %s", typ, n.IsBlock(), n.IsInline(), kind)
+		}
+		return nil
+	})
+	if len(seen) != len(kinds) {
+		t.Errorf("found node types %v, want all of %v", seen, kinds)
+	}
+}
+
+func TestGFMValidate(t *testing.T) {
+	doc := parseGFM(t, gfmTable)
+	defer doc.Close()
+	if err := doc.Validate(); err != nil {
+		t.Errorf("Validate: %v", err)
+	}
+	if !IsValidMarkdown(gfmTable, OptDefault) {
+		t.Error("IsValidMarkdown reported a table as invalid")
+	}
+	if !doc.CanContain(NodeTable) {
+		t.Error("a document cannot contain a table")
+	}
+	table := doc.FirstChild()
+	if table.CanContain(NodeParagraph) || !table.CanContain(NodeTableRow) {
+		t.Error("a table may only contain rows")
+	}
+}
+
+func TestGFMSplitAtHeadings(t *testing.T) {
+	doc := parseGFM(t, "# One\n\n"+gfmTable+"\n# Two\n\n~~gone~~\n")
+	defer doc.Close()
+	sections, err := SplitAtHeadings(doc, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"<h1>One</h1>\n<table>\n<thead>\n<tr>\n<th>a</th>\n<th align=\"center\">b</th>\n</tr>\n</thead>\n<tbody>\n<tr>\n<td>1</td>\n<td align=\"center\"><del>2</del></td>\n</tr>\n</tbody>\n</table>\n",
+		"<h1>Two</h1>\n<p><del>gone</del></p>\n",
+	}
+	if len(sections) != len(want) {
+		t.Fatalf("got %d sections, want %d", len(sections), len(want))
+	}
+	for i, s := range sections {
+		if html := s.RenderHTML(OptDefault); html != want[i] {
+			t.Errorf("section %d = %q, want %q", i, html, want[i])
+		}
+		s.Close()
+	}
+}
+
+func TestGFMJSONRoundTrip(t *testing.T) {
+	if err := RegisterTasklistExtension(); err != nil {
+		t.Fatal(err)
+	}
+	if err := RegisterFootnoteExtension(); err != nil {
+		t.Fatal(err)
+	}
+	doc := parseGFM(t, gfmTable+"\n- [x] done\n- [ ] todo\n\nNote[^1]\n\n[^1]: The note\n")
+	defer doc.Close()
+	for _, typ := range []NodeType{NodeTable, NodeTableRow, NodeTableCell, NodeStrikethrough, NodeFootnoteDefinition, NodeFootnoteReference} {
+		if name := typ.String(); name == "<unknown>" {
+			t.Errorf("%d has no name", typ)
+		} else if got, err := parseNodeType(name); err != nil || got != typ {
+			t.Errorf("parseNodeType(%q) = %v, %v", name, got, err)
+		}
+	}
+	data, err := json.Marshal(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var back Node
+	if err := json.Unmarshal(data, &back); err != nil {
+		t.Fatal(err)
+	}
+	defer back.Close()
+	if !back.Equal(doc) {
+		t.Errorf("round trip changed the document:\n%s", data)
+	}
+	if got, want := back.RenderHTML(OptDefault), doc.RenderHTML(OptDefault); got != want {
+		t.Errorf("round trip renders\n%s\nwant\n%s", got, want)
+	}
+}
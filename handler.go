@@ -0,0 +1,29 @@
+package cmark
+
+import (
+	"net/http"
+	"strconv"
+)
+
+// HTMLHandler serves the html rendering of Node with Options
+// The node must stay open while the handler is in use, and must not be
+// modified while requests are being served
+type HTMLHandler struct {
+	Node    Node
+	Options Opt
+}
+
+// ServeHTTP implements http.Handler, rendering the whole page before
+// writing it so that a failed render can be reported with a 500
+func (h HTMLHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if h.Node.node == nil {
+		http.Error(w, "cmark: no document to render", http.StatusInternalServerError)
+		return
+	}
+	html := h.Node.RenderHTML(h.Options)
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Content-Length", strconv.Itoa(len(html)))
+	if r.Method != http.MethodHead {
+		w.Write([]byte(html))
+	}
+}
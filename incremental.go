@@ -0,0 +1,69 @@
+package cmark
+
+// IncrementalParser keeps a document's source and its tree up to date
+// as the source is edited, for use by editors
+//
+// It is a full re-parse helper: cmark cannot resume a parse part way
+// through a document, and a link reference definition anywhere can
+// change how the rest of it parses, so the tree is rebuilt from the
+// whole source. Only edits are cheap, the tree is rebuilt when Tree
+// is called after an edit, so any number of edits may be made between
+// parses
+type IncrementalParser struct {
+	opts  Opt
+	src   []byte
+	tree  Node
+	dirty bool
+}
+
+// NewIncrementalParser returns a parser for an empty document,
+// call Close when finished
+func NewIncrementalParser(opts Opt) *IncrementalParser {
+	return &IncrementalParser{opts: opts, dirty: true}
+}
+
+// Edit replaces the source bytes from startByte up to endByte with
+// newText, a zero length range inserts newText
+// If the range is not within the source nothing is changed and
+// ErrEditRange is returned
+func (p *IncrementalParser) Edit(startByte, endByte int, newText []byte) error {
+	if startByte < 0 || startByte > endByte || endByte > len(p.src) {
+		return ErrEditRange
+	}
+	src := make([]byte, 0, len(p.src)-(endByte-startByte)+len(newText))
+	src = append(src, p.src[:startByte]...)
+	src = append(src, newText...)
+	p.src = append(src, p.src[endByte:]...)
+	p.dirty = true
+	return nil
+}
+
+// Source returns the current source, it must not be modified
+func (p *IncrementalParser) Source() []byte {
+	return p.src
+}
+
+// Tree returns the document for the current source
+// The tree is owned by the parser and must not be closed by the caller,
+// it is freed by Close or by the next call to Tree after an Edit
+func (p *IncrementalParser) Tree() Node {
+	if p.dirty {
+		parser := NewParser(p.opts)
+		tree := parser.ParseBytes(p.src)
+		parser.Close()
+		if p.tree.node != nil {
+			p.tree.Close()
+		}
+		p.tree, p.dirty = tree, false
+	}
+	return p.tree
+}
+
+// Close frees the current tree
+func (p *IncrementalParser) Close() {
+	if p.tree.node != nil {
+		p.tree.Close()
+		p.tree = Node{}
+	}
+	p.dirty = true
+}
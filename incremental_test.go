@@ -0,0 +1,29 @@
+package cmark
+
+import "testing"
+
+func TestIncrementalParserEdit(t *testing.T) {
+	p := NewIncrementalParser(OptDefault)
+	defer p.Close()
+	edits := []struct {
+		start, end int
+		text       string
+		want       string
+		err        error
+	}{
+		{0, 0, "# Title\n", "# Title\n", nil},
+		{2, 7, "Head", "# Head\n", nil},
+		{6, 6, "\n\ntext", "# Head\n\ntext\n", nil},
+		{-1, 0, "x", "# Head\n\ntext\n", ErrEditRange},
+		{3, 2, "x", "# Head\n\ntext\n", ErrEditRange},
+		{0, 100, "x", "# Head\n\ntext\n", ErrEditRange},
+	}
+	for _, e := range edits {
+		if err := p.Edit(e.start, e.end, []byte(e.text)); err != e.err {
+			t.Errorf("Edit(%d, %d) = %v, want %v", e.start, e.end, err, e.err)
+		}
+		if got := string(p.Source()); got != e.want {
+			t.Errorf("after Edit(%d, %d) source = %q, want %q", e.start, e.end, got, e.want)
+		}
+	}
+}
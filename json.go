@@ -0,0 +1,265 @@
+package cmark
+
+import (
+	"encoding/json"
+	"errors"
+)
+
+// nodeTypes lists every NodeType constant
+var nodeTypes = []NodeType{
+	NodeDocument, NodeBlockQuote, NodeList, NodeItem, NodeCodeBlock,
+	NodeHTMLBlock, NodeCustomBlock, NodeParagraph, NodeHeading,
+	NodeThematicBreak, NodeText, NodeSoftBreak, NodeLineBreak, NodeCode,
+	NodeHTMLInline, NodeCustomInline, NodeEmph, NodeStrong, NodeLink,
+	NodeImage,
+}
+
+// parseNodeType is the inverse of NodeType.String
+func parseNodeType(s string) (NodeType, error) {
+	for _, t := range nodeTypes {
+		if t.String() == s {
+			return t, nil
+		}
+	}
+	for _, t := range extensionNodeTypes() {
+		if t.String() == s {
+			return t, nil
+		}
+	}
+	return NodeNone, errors.New("Unknown node type " + s)
+}
+
+func parseListType(s string) ListType {
+	switch s {
+	case "bullet":
+		return BulletList
+	case "ordered":
+		return OrderedList
+	}
+	return _NoList
+}
+
+func parseListDelim(s string) ListDelim {
+	switch s {
+	case "period":
+		return PeriodDelim
+	case "paren":
+		return ParenDelim
+	}
+	return _NoDelim
+}
+
+// nodeAttributes are the type, literal and attributes of a node
+type nodeAttributes struct {
+	Type      string `json:"type"`
+	Literal   string `json:"literal,omitempty"`
+	Level     int    `json:"level,omitempty"`
+	ListType  string `json:"list_type,omitempty"`
+	ListDelim string `json:"list_delim,omitempty"`
+	ListStart int    `json:"list_start,omitempty"`
+	Tight     bool   `json:"tight,omitempty"`
+	Info      string `json:"info,omitempty"`
+	URL       string `json:"url,omitempty"`
+	Title     string `json:"title,omitempty"`
+	OnEnter   string `json:"on_enter,omitempty"`
+	OnExit    string `json:"on_exit,omitempty"`
+	// Attributes of the cmark-gfm extensions
+	// Alignments has one of l, c, r or n (none) for each table column
+	Task       bool   `json:"task,omitempty"`
+	Checked    bool   `json:"checked,omitempty"`
+	Alignments string `json:"alignments,omitempty"`
+	Header     bool   `json:"header,omitempty"`
+	Label      string `json:"label,omitempty"`
+}
+
+// jsonNode is the JSON representation of a node
+type jsonNode struct {
+	nodeAttributes
+	Children []jsonNode `json:"children,omitempty"`
+}
+
+func attributesOf(n Node) nodeAttributes {
+	typ, _ := n.Type()
+	j := nodeAttributes{Type: typ.String()}
+	switch typ {
+	case NodeText, NodeCode, NodeHTMLBlock, NodeHTMLInline:
+		j.Literal = n.Literal()
+	case NodeCodeBlock:
+		j.Literal = n.Literal()
+		j.Info = n.FenceInfo()
+	case NodeHeading:
+		j.Level, _ = n.HeadingLevel()
+	case NodeList:
+		lt, _ := n.ListType()
+		j.ListType = lt.String()
+		if lt == OrderedList {
+			ld, _ := n.ListDelim()
+			j.ListDelim = ld.String()
+			j.ListStart, _ = n.ListStart()
+		}
+		j.Tight = n.TightList()
+	case NodeLink, NodeImage:
+		j.URL = n.URL()
+		j.Title = n.Title()
+	case NodeCustomBlock, NodeCustomInline:
+		j.OnEnter = n.OnEnter()
+		j.OnExit = n.OnExit()
+	}
+	extensionAttributes(n, typ, &j)
+	return j
+}
+
+// values returns the node specific attributes of a node of type typ
+// keyed as in MarshalJSON, ToMap and ToAST use them
+func (a nodeAttributes) values(typ NodeType) map[string]interface{} {
+	m := map[string]interface{}{}
+	switch typ {
+	case NodeCodeBlock:
+		m["info"] = a.Info
+	case NodeHeading:
+		m["level"] = a.Level
+	case NodeList:
+		m["list_type"] = a.ListType
+		if a.ListDelim != "" {
+			m["list_delim"] = a.ListDelim
+			m["list_start"] = a.ListStart
+		}
+		m["tight"] = a.Tight
+	case NodeLink, NodeImage:
+		m["url"] = a.URL
+		m["title"] = a.Title
+	case NodeCustomBlock, NodeCustomInline:
+		m["on_enter"] = a.OnEnter
+		m["on_exit"] = a.OnExit
+	}
+	if a.Task {
+		m["checked"] = a.Checked
+	}
+	if a.Alignments != "" {
+		m["alignments"] = a.Alignments
+	}
+	if a.Header {
+		m["header"] = true
+	}
+	if a.Label != "" {
+		m["label"] = a.Label
+	}
+	return m
+}
+
+func toJSONNode(n Node) jsonNode {
+	j := jsonNode{nodeAttributes: attributesOf(n)}
+	for c := n.FirstChild(); c.node != nil; c = c.Next() {
+		j.Children = append(j.Children, toJSONNode(c))
+	}
+	return j
+}
+
+// fromJSONNode builds a new tree from j, on error nothing is allocated
+func fromJSONNode(j jsonNode) (Node, error) {
+	typ, err := parseNodeType(j.Type)
+	if err != nil {
+		return Node{}, err
+	}
+	n := newNodeFor(typ, j.nodeAttributes)
+	if err := n.setJSONAttributes(typ, j); err != nil {
+		n.Close()
+		return Node{}, err
+	}
+	for _, jc := range j.Children {
+		c, err := fromJSONNode(jc)
+		if err == nil {
+			err = n.AppendChild(c)
+			if err != nil {
+				c.Close()
+			}
+		}
+		if err != nil {
+			n.Close()
+			return Node{}, err
+		}
+	}
+	return n, nil
+}
+
+func (n Node) setJSONAttributes(typ NodeType, j jsonNode) error {
+	switch typ {
+	case NodeText, NodeCode, NodeHTMLBlock, NodeHTMLInline:
+		n.SetLiteral(j.Literal)
+	case NodeCodeBlock:
+		n.SetLiteral(j.Literal)
+		return n.SetFenceInfo(j.Info)
+	case NodeHeading:
+		return n.SetHeadingLevel(j.Level)
+	case NodeList:
+		if err := n.SetListType(parseListType(j.ListType)); err != nil {
+			return err
+		}
+		if j.ListDelim != "" {
+			if err := n.SetListDelim(parseListDelim(j.ListDelim)); err != nil {
+				return err
+			}
+			if err := n.SetListStart(j.ListStart); err != nil {
+				return err
+			}
+		}
+		return n.SetTightList(j.Tight)
+	case NodeLink, NodeImage:
+		if err := n.SetURL(j.URL); err != nil {
+			return err
+		}
+		return n.SetTitle(j.Title)
+	case NodeCustomBlock, NodeCustomInline:
+		if err := n.SetOnEnter(j.OnEnter); err != nil {
+			return err
+		}
+		return n.SetOnExit(j.OnExit)
+	}
+	return setExtensionAttributes(n, typ, j.nodeAttributes)
+}
+
+// MarshalJSON serializes the subtree of this node as JSON
+// including each node's type, literal, attributes and children
+func (n Node) MarshalJSON() ([]byte, error) {
+	if n.node == nil {
+		return []byte("null"), nil
+	}
+	return json.Marshal(toJSONNode(n))
+}
+
+// UnmarshalJSON builds a new tree from JSON produced by MarshalJSON
+// and points n at its root, call Close on n when finished
+func (n *Node) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+	var j jsonNode
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+	root, err := fromJSONNode(j)
+	if err != nil {
+		return err
+	}
+	*n = root
+	return nil
+}
+
+// Equal reports whether two subtrees have the same structure, with the
+// same node types, literals and attributes
+// Source positions and user data are not compared
+func (n Node) Equal(other Node) bool {
+	if n.node == nil || other.node == nil {
+		return n.node == other.node
+	}
+	if attributesOf(n) != attributesOf(other) {
+		return false
+	}
+	c, d := n.FirstChild(), other.FirstChild()
+	for ; c.node != nil && d.node != nil; c, d = c.Next(), d.Next() {
+		if !c.Equal(d) {
+			return false
+		}
+	}
+	return c.node == nil && d.node == nil
+}
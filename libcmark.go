@@ -0,0 +1,84 @@
+//go:build cgo && !cmark_gfm
+
+package cmark
+
+// #cgo LDFLAGS: -lcmark
+// #include "cmark_go.h"
+import "C"
+
+// cmarkVersion returns the version of the linked libcmark, e.g. "0.31.1"
+func cmarkVersion() string {
+	return C.GoString(C.cmark_version_string())
+}
+
+func newParser(options Opt) *C.cmark_parser {
+	return C.cmark_parser_new(C.int(options))
+}
+
+func newParserWithMem(options Opt, mem *C.cmark_mem) *C.cmark_parser {
+	return C.cmark_parser_new_with_mem(C.int(options), mem)
+}
+
+func renderHTML(node *C.cmark_node, options C.int) *C.char {
+	return C.cmark_render_html(node, options)
+}
+
+func markdownToHTML(text *C.char, size C.size_t, options Opt) *C.char {
+	return C.cmark_markdown_to_html(text, size, C.int(options))
+}
+
+// fenceLengthUnsupported is the SetFenceLength error message
+const fenceLengthUnsupported = "fence lengths need cmark-gfm"
+
+// cmark does not expose fence lengths
+func fenceLength(node *C.cmark_node) int {
+	return -1
+}
+
+func setFenceLength(node *C.cmark_node, length int) bool {
+	return false
+}
+
+func isBlockType(t NodeType) bool {
+	return t >= NodeFirstBlock && t <= NodeLastBlock
+}
+
+func isInlineType(t NodeType) bool {
+	return t >= NodeFirstInline && t <= NodeLastInline
+}
+
+// cmark has no extensions
+func isExtensionNodeType(t NodeType) bool {
+	return false
+}
+
+func extensionCanContain(parent, child NodeType) (can, ok bool) {
+	return false, false
+}
+
+func newNodeLike(n Node, typ NodeType) Node {
+	return NewNode(typ)
+}
+
+func copyExtensionAttributes(dst, src Node, typ NodeType) error {
+	return nil
+}
+
+func extensionNodeTypes() []NodeType {
+	return nil
+}
+
+func extensionTypeName(t NodeType) string {
+	return "<unknown>"
+}
+
+func newNodeFor(typ NodeType, a nodeAttributes) Node {
+	return NewNode(typ)
+}
+
+func extensionAttributes(n Node, typ NodeType, a *nodeAttributes) {
+}
+
+func setExtensionAttributes(n Node, typ NodeType, a nodeAttributes) error {
+	return nil
+}
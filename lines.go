@@ -0,0 +1,56 @@
+package cmark
+
+import "errors"
+
+// errLineParserFinished is returned when a LineParser is used after
+// Finish or Close
+var errLineParserFinished = errors.New("LineParser is finished")
+
+// LineParser parses a document given one line at a time, such as the
+// lines returned by a bufio.Scanner
+type LineParser struct {
+	parser Parser
+	done   bool
+}
+
+// NewLineParser returns a parser for a new document, call Finish to
+// get the document or Close to abandon it
+func NewLineParser(opts Opt) *LineParser {
+	return &LineParser{parser: NewParser(opts)}
+}
+
+// FeedLine adds a line, without its line ending, to the document
+func (p *LineParser) FeedLine(line string) error {
+	if p.done {
+		return errLineParserFinished
+	}
+	if _, err := p.parser.WriteString(line); err != nil {
+		return err
+	}
+	_, err := p.parser.WriteString("\n")
+	return err
+}
+
+// Finish returns the document and frees the parser, call Close on the
+// document when finished
+func (p *LineParser) Finish() (Node, error) {
+	if p.done {
+		return Node{}, errLineParserFinished
+	}
+	p.done = true
+	doc := p.parser.Tree()
+	p.parser.Close()
+	if doc.node == nil {
+		return Node{}, errors.New("Parser did not produce a document")
+	}
+	return doc, nil
+}
+
+// Close frees the parser and any lines fed to it, it does nothing
+// after Finish
+func (p *LineParser) Close() {
+	if !p.done {
+		p.done = true
+		p.parser.Close()
+	}
+}
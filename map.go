@@ -0,0 +1,111 @@
+package cmark
+
+import "fmt"
+
+// ToMap copies the subtree of this node into maps which encoding/json
+// can marshal directly
+//
+// Each map has a "type" and, when not empty, a "literal" and
+// "children", along with the node's attributes under the same keys as
+// MarshalJSON uses, "start_line", "start_column", "end_line" and
+// "end_column" are set when source positions were recorded
+func (n Node) ToMap() map[string]interface{} {
+	typ, _ := n.Type()
+	m := attributesOf(n).values(typ)
+	m["type"] = n.TypeString()
+	if lit := n.Literal(); lit != "" {
+		m["literal"] = lit
+	}
+	if r := n.Range(); r.StartLine != 0 {
+		m["start_line"] = r.StartLine
+		m["start_column"] = r.StartColumn
+		m["end_line"] = r.EndLine
+		m["end_column"] = r.EndColumn
+	}
+	var children []interface{}
+	for c := n.FirstChild(); c.node != nil; c = c.Next() {
+		children = append(children, c.ToMap())
+	}
+	if children != nil {
+		m["children"] = children
+	}
+	return m
+}
+
+// FromMap builds a new tree from maps in the form ToMap returns,
+// including maps decoded by encoding/json, call Close when finished
+// Source positions are ignored
+func FromMap(m map[string]interface{}) (Node, error) {
+	j, err := jsonNodeFromMap(m)
+	if err != nil {
+		return Node{}, err
+	}
+	return fromJSONNode(j)
+}
+
+func jsonNodeFromMap(m map[string]interface{}) (jsonNode, error) {
+	var j jsonNode
+	var err error
+	str := func(key string) string {
+		v, ok := m[key]
+		if !ok || err != nil {
+			return ""
+		}
+		s, ok := v.(string)
+		if !ok {
+			err = fmt.Errorf("Map key %q is %T, not a string", key, v)
+		}
+		return s
+	}
+	num := func(key string) int {
+		v, ok := m[key]
+		if !ok || err != nil {
+			return 0
+		}
+		switch v := v.(type) {
+		case int:
+			return v
+		case float64:
+			return int(v)
+		}
+		err = fmt.Errorf("Map key %q is %T, not a number", key, v)
+		return 0
+	}
+	j.Type = str("type")
+	j.Literal = str("literal")
+	j.Level = num("level")
+	j.ListType = str("list_type")
+	j.ListDelim = str("list_delim")
+	j.ListStart = num("list_start")
+	if v, ok := m["tight"]; ok {
+		if j.Tight, ok = v.(bool); !ok {
+			err = fmt.Errorf("Map key %q is %T, not a bool", "tight", v)
+		}
+	}
+	j.Info = str("info")
+	j.URL = str("url")
+	j.Title = str("title")
+	j.OnEnter = str("on_enter")
+	j.OnExit = str("on_exit")
+	if err != nil {
+		return jsonNode{}, err
+	}
+	if v, ok := m["children"]; ok {
+		children, ok := v.([]interface{})
+		if !ok {
+			return jsonNode{}, fmt.Errorf("Map key %q is %T, not a list", "children", v)
+		}
+		for _, c := range children {
+			cm, ok := c.(map[string]interface{})
+			if !ok {
+				return jsonNode{}, fmt.Errorf("Child is %T, not a map", c)
+			}
+			jc, err := jsonNodeFromMap(cm)
+			if err != nil {
+				return jsonNode{}, err
+			}
+			j.Children = append(j.Children, jc)
+		}
+	}
+	return j, nil
+}
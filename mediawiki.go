@@ -0,0 +1,155 @@
+package cmark
+
+import (
+	"html"
+	"strings"
+)
+
+// RenderMediaWiki renders the document as MediaWiki markup, e.g.
+//
+//	== Level 1 heading ==
+//	'''strong''' ''emphasis'' <code>code</code>
+//	[https://example.com external link] [[Page|internal link]]
+//
+// Code blocks are in <pre> tags and lists use "*" and "#"
+// Text is not escaped, so wiki markup in the text is kept
+func (n Node) RenderMediaWiki(options Opt) string {
+	r := mediaWikiRenderer{opts: options}
+	Walk(n, func(n Node, ev Event) error {
+		r.event(n, ev)
+		return nil
+	})
+	return r.out.String()
+}
+
+type mediaWikiRenderer struct {
+	opts Opt
+	out  strings.Builder
+	// markers are the list markers of the enclosing lists, e.g. "*#"
+	markers string
+	// attached is set when the next block directly follows a list marker
+	attached bool
+}
+
+func (r *mediaWikiRenderer) event(n Node, ev Event) {
+	typ, _ := n.Type()
+	enter := ev == EventEnter
+	switch typ {
+	case NodeParagraph:
+		if enter {
+			r.startBlock(n)
+		} else {
+			r.out.WriteString("\n")
+		}
+	case NodeHeading:
+		level, _ := n.HeadingLevel()
+		marks := strings.Repeat("=", level+1)
+		if enter {
+			r.startBlock(n)
+			r.out.WriteString(marks + " ")
+		} else {
+			r.out.WriteString(" " + marks + "\n")
+		}
+	case NodeBlockQuote:
+		if enter {
+			r.startBlock(n)
+			r.out.WriteString("<blockquote>\n")
+		} else {
+			r.out.WriteString("</blockquote>\n")
+		}
+	case NodeList:
+		if enter {
+			if ptyp, _ := n.Parent().Type(); ptyp != NodeItem {
+				r.startBlock(n)
+			}
+			marker := "*"
+			if typ, _ := n.ListType(); typ == OrderedList {
+				marker = "#"
+			}
+			r.markers += marker
+		} else {
+			r.markers = r.markers[:len(r.markers)-1]
+		}
+	case NodeItem:
+		if enter {
+			r.out.WriteString(r.markers + " ")
+			r.attached = true
+		} else if r.attached {
+			// an empty item
+			r.out.WriteString("\n")
+			r.attached = false
+		}
+	case NodeCodeBlock:
+		r.startBlock(n)
+		r.out.WriteString("<pre>" + html.EscapeString(strings.TrimSuffix(n.Literal(), "\n")) + "</pre>\n")
+	case NodeHTMLBlock:
+		r.startBlock(n)
+		r.out.WriteString(withNewline(n.Literal()))
+	case NodeThematicBreak:
+		r.startBlock(n)
+		r.out.WriteString("----\n")
+	case NodeText, NodeHTMLInline:
+		r.out.WriteString(n.Literal())
+	case NodeCode:
+		r.out.WriteString("<code>" + html.EscapeString(n.Literal()) + "</code>")
+	case NodeSoftBreak:
+		switch {
+		case r.opts&OptHardBreaks != 0:
+			r.lineBreak()
+		case r.opts&OptNoBreaks != 0 || r.markers != "":
+			// a newline would end the list item
+			r.out.WriteString(" ")
+		default:
+			r.out.WriteString("\n")
+		}
+	case NodeLineBreak:
+		r.lineBreak()
+	case NodeEmph:
+		r.out.WriteString("''")
+	case NodeStrong:
+		r.out.WriteString("'''")
+	case NodeLink:
+		url := n.URL()
+		external := strings.Contains(url, "://") || strings.HasPrefix(url, "mailto:")
+		switch {
+		case enter && external:
+			r.out.WriteString("[" + url + " ")
+		case enter:
+			r.out.WriteString("[[" + url + "|")
+		case external:
+			r.out.WriteString("]")
+		default:
+			r.out.WriteString("]]")
+		}
+	case NodeImage:
+		if enter {
+			r.out.WriteString("[[File:" + n.URL() + "|")
+		} else {
+			r.out.WriteString("]]")
+		}
+	}
+}
+
+// startBlock separates a block from the one before it, blocks after
+// the first in a list item are continued with a ":" marker
+func (r *mediaWikiRenderer) startBlock(n Node) {
+	if r.attached {
+		r.attached = false
+		return
+	}
+	if ptyp, _ := n.Parent().Type(); ptyp == NodeItem {
+		r.out.WriteString(r.markers + ": ")
+		return
+	}
+	if r.out.Len() > 0 {
+		r.out.WriteString("\n")
+	}
+}
+
+func (r *mediaWikiRenderer) lineBreak() {
+	if r.markers != "" {
+		r.out.WriteString("<br />")
+	} else {
+		r.out.WriteString("<br />\n")
+	}
+}
@@ -0,0 +1,75 @@
+package cmark
+
+import "strings"
+
+var migrateToGFM = ComposeTransforms(
+	migrateHTMLBreaks,
+)
+
+// MigrateToGFM rewrites the subtree of this node to use GFM-compatible
+// constructs: raw <hr> and <br> html is replaced by thematic breaks and
+// line breaks
+//
+// Headings need no rewriting, cmark does not record setext vs ATX style
+// and RenderCommonMark always emits ATX headings
+// Code blocks are left alone, GFM supports indented code and giving
+// them an info string would claim a language they do not have
+func (n Node) MigrateToGFM() error {
+	_, err := migrateToGFM(n)
+	return err
+}
+
+// collectNodes returns every node under root of the given types,
+// so that the tree can be safely modified afterwards
+func collectNodes(root Node, types ...NodeType) []Node {
+	var nodes []Node
+	iter := root.Iter()
+	defer iter.Close()
+	for ev := iter.Next(); ev != EventDone; ev = iter.Next() {
+		if ev != EventEnter {
+			continue
+		}
+		node := iter.Node()
+		typ, _ := node.Type()
+		for _, t := range types {
+			if typ == t {
+				nodes = append(nodes, node)
+				break
+			}
+		}
+	}
+	return nodes
+}
+
+// isHTMLTag reports whether html is a lone void tag such as <br> or <hr />
+func isHTMLTag(html, tag string) bool {
+	html = strings.ToLower(strings.TrimSpace(html))
+	if !strings.HasPrefix(html, "<"+tag) || !strings.HasSuffix(html, ">") {
+		return false
+	}
+	rest := strings.TrimSpace(html[len(tag)+1 : len(html)-1])
+	return rest == "" || rest == "/"
+}
+
+// migrateHTMLBreaks replaces raw <hr> html blocks with thematic breaks
+// and raw <br> inline html with line breaks
+func migrateHTMLBreaks(root Node) (Node, error) {
+	for _, html := range collectNodes(root, NodeHTMLBlock, NodeHTMLInline) {
+		typ, _ := html.Type()
+		var repl Node
+		switch {
+		case typ == NodeHTMLBlock && isHTMLTag(html.Literal(), "hr"):
+			repl = NewNode(NodeThematicBreak)
+		case typ == NodeHTMLInline && isHTMLTag(html.Literal(), "br"):
+			repl = NewNode(NodeLineBreak)
+		default:
+			continue
+		}
+		if err := html.Replace(repl); err != nil {
+			repl.Close()
+			return root, err
+		}
+		html.Close()
+	}
+	return root, nil
+}
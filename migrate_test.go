@@ -0,0 +1,25 @@
+package cmark
+
+import "testing"
+
+func TestIsHTMLTag(t *testing.T) {
+	tests := []struct {
+		html, tag string
+		want      bool
+	}{
+		{"<br>", "br", true},
+		{"<BR/>", "br", true},
+		{" <br /> \n", "br", true},
+		{"<hr>", "hr", true},
+		{"<br>", "hr", false},
+		{"<br class=\"x\">", "br", false},
+		{"<bra>", "br", false},
+		{"<br>text", "br", false},
+		{"</br>", "br", false},
+	}
+	for _, tt := range tests {
+		if got := isHTMLTag(tt.html, tt.tag); got != tt.want {
+			t.Errorf("isHTMLTag(%q, %q) = %v, want %v", tt.html, tt.tag, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,163 @@
+package cmark
+
+import (
+	"fmt"
+	"strings"
+)
+
+var optNames = []struct {
+	opt  Opt
+	name string
+}{
+	{OptSourcePos, "SOURCEPOS"},
+	{OptHardBreaks, "HARDBREAKS"},
+	{OptSafe, "SAFE"},
+	{OptNoBreaks, "NOBREAKS"},
+	{OptValidateUtf8, "VALIDATE_UTF8"},
+	{OptSmart, "SMART"},
+}
+
+// String returns the set options separated by pipes, e.g. "SOURCEPOS|SMART"
+// Unknown bits are printed in hex
+func (o Opt) String() string {
+	if o == OptDefault {
+		return "DEFAULT"
+	}
+	var names []string
+	for _, on := range optNames {
+		if o&on.opt != 0 {
+			names = append(names, on.name)
+			o &^= on.opt
+		}
+	}
+	if o != 0 {
+		names = append(names, fmt.Sprintf("%#x", int(o)))
+	}
+	return strings.Join(names, "|")
+}
+
+// Opts combines options, e.g. Opts(OptSourcePos, OptSmart)
+func Opts(opts ...Opt) Opt {
+	o := OptDefault
+	for _, opt := range opts {
+		o |= opt
+	}
+	return o
+}
+
+// Add returns o with the options in other set, o.Add(other) is the
+// same as o | other
+// Options are bit flags, adding them with + is wrong when an option is
+// already set
+func (o Opt) Add(other Opt) Opt {
+	return o | other
+}
+
+// Remove returns o with the options in other cleared,
+// the same as o &^ other
+func (o Opt) Remove(other Opt) Opt {
+	return o &^ other
+}
+
+// Has returns true if any of the options in other are set in o,
+// the same as o&other != 0
+func (o Opt) Has(other Opt) bool {
+	return o&other != 0
+}
+
+// String returns the same name as TypeString, e.g. "heading",
+// without calling into cmark for the NodeType constants
+// The node types of the cmark-gfm extensions are named by cmark-gfm
+func (t NodeType) String() string {
+	switch t {
+	case NodeNone:
+		return "NONE"
+	case NodeDocument:
+		return "document"
+	case NodeBlockQuote:
+		return "block_quote"
+	case NodeList:
+		return "list"
+	case NodeItem:
+		return "item"
+	case NodeCodeBlock:
+		return "code_block"
+	case NodeHTMLBlock:
+		return "html_block"
+	case NodeCustomBlock:
+		return "custom_block"
+	case NodeParagraph:
+		return "paragraph"
+	case NodeHeading:
+		return "heading"
+	case NodeThematicBreak:
+		return "thematic_break"
+	case NodeText:
+		return "text"
+	case NodeSoftBreak:
+		return "softbreak"
+	case NodeLineBreak:
+		return "linebreak"
+	case NodeCode:
+		return "code"
+	case NodeHTMLInline:
+		return "html_inline"
+	case NodeCustomInline:
+		return "custom_inline"
+	case NodeEmph:
+		return "emph"
+	case NodeStrong:
+		return "strong"
+	case NodeLink:
+		return "link"
+	case NodeImage:
+		return "image"
+	}
+	return extensionTypeName(t)
+}
+
+// IsValid returns true if t is one of the NodeType constants other than
+// NodeNone, or when built with cmark_gfm a node type of the core
+// extensions
+func (t NodeType) IsValid() bool {
+	for _, typ := range nodeTypes {
+		if t == typ {
+			return true
+		}
+	}
+	return isExtensionNodeType(t)
+}
+
+func (l ListType) String() string {
+	switch l {
+	case BulletList:
+		return "bullet"
+	case OrderedList:
+		return "ordered"
+	}
+	return "none"
+}
+
+func (d ListDelim) String() string {
+	switch d {
+	case PeriodDelim:
+		return "period"
+	case ParenDelim:
+		return "paren"
+	}
+	return "none"
+}
+
+func (e Event) String() string {
+	switch e {
+	case EventNone:
+		return "none"
+	case EventDone:
+		return "done"
+	case EventEnter:
+		return "enter"
+	case EventExit:
+		return "exit"
+	}
+	return "<unknown>"
+}
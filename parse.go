@@ -0,0 +1,117 @@
+package cmark
+
+import (
+	"context"
+	"errors"
+	"io"
+)
+
+// MinChunkSize is the smallest chunk size accepted by ParseBytesStream
+const MinChunkSize = 512
+
+// ParseBytesStream parses a document from r, feeding the parser
+// chunkSize bytes at a time
+// chunkSize must be at least MinChunkSize
+//
+// The returned node is the document root, call Close when finished
+func ParseBytesStream(r io.Reader, chunkSize int, opts Opt) (Node, error) {
+	if chunkSize < MinChunkSize {
+		return Node{}, errors.New("Chunk size is smaller than MinChunkSize")
+	}
+	p := NewParser(opts)
+	defer p.Close()
+	buf := make([]byte, chunkSize)
+	for {
+		n, err := io.ReadFull(r, buf)
+		p.Write(buf[:n])
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			break
+		}
+		if err != nil {
+			p.Tree().Close()
+			return Node{}, err
+		}
+	}
+	return p.Tree(), nil
+}
+
+// ParseReader reads all of r and parses it as one document
+//
+// The returned node is the document root, call Close when finished
+func ParseReader(r io.Reader, opts Opt) (Node, error) {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return Node{}, err
+	}
+	p := NewParser(opts)
+	defer p.Close()
+	root := p.ParseBytes(b)
+	if root.node == nil {
+		return Node{}, errors.New("Parser did not produce a document")
+	}
+	return root, nil
+}
+
+// ParseBytesContext parses b, returning ctx.Err() if ctx is cancelled
+// before all of b has been written
+//
+// The returned node is the document root, call Close when finished
+func ParseBytesContext(ctx context.Context, b []byte, opts Opt) (Node, error) {
+	p := NewParser(opts)
+	defer p.Close()
+	if _, err := p.WriteContext(ctx, b); err != nil {
+		p.Tree().Close()
+		return Node{}, err
+	}
+	return p.Tree(), nil
+}
+
+// IsValidMarkdown reports whether s parses to a document
+//
+// cmark accepts any input as CommonMark, so this only fails if cmark
+// could not produce a document tree (e.g. when out of memory), it does
+// not detect markdown that renders differently than intended
+func IsValidMarkdown(s string, opts Opt) bool {
+	p := NewParser(opts)
+	defer p.Close()
+	root := p.ParseString(s)
+	if root.node == nil {
+		return false
+	}
+	defer root.Close()
+	return root.Validate() == nil
+}
+
+// normalizePasses bounds how often RenderCommonMarkNormalized re-renders
+// its output looking for a fixed point
+const normalizePasses = 4
+
+// RenderCommonMarkNormalized renders canonical CommonMark, which parses
+// back to a document rendering the same text
+// Rendering is done without OptSmart so quotes and dashes are kept as
+// written, and repeated while the output is not yet stable
+func (n Node) RenderCommonMarkNormalized(wrapWidth int) string {
+	out := n.RenderCommonMark(OptDefault, wrapWidth)
+	for i := 0; i < normalizePasses; i++ {
+		p := NewParser(OptDefault)
+		doc := p.ParseString(out)
+		p.Close()
+		again := doc.RenderCommonMark(OptDefault, wrapWidth)
+		doc.Close()
+		if again == out {
+			break
+		}
+		out = again
+	}
+	return out
+}
+
+// IsNormalized reports whether markdown is already in the form
+// RenderCommonMarkNormalized produces
+func IsNormalized(markdown string, wrapWidth int) bool {
+	p := NewParser(OptDefault)
+	defer p.Close()
+	doc := p.ParseString(markdown)
+	defer doc.Close()
+	return doc.RenderCommonMarkNormalized(wrapWidth) == markdown
+}
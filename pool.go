@@ -0,0 +1,68 @@
+package cmark
+
+import (
+	"runtime"
+	"sync"
+)
+
+// ParserPool is a goroutine-safe pool of parsers sharing the same options
+//
+// A Parser must only be used by one goroutine at a time, ParserPool
+// lets many goroutines parse concurrently without allocating a parser
+// per document
+type ParserPool struct {
+	pool sync.Pool
+}
+
+// NewParserPool creates a pool of parsers with the given options,
+// size parsers are allocated up front
+func NewParserPool(options Opt, size int) *ParserPool {
+	pp := &ParserPool{}
+	pp.pool.New = func() interface{} {
+		return pooledParser(NewParser(options))
+	}
+	for i := 0; i < size; i++ {
+		pp.pool.Put(pp.pool.New())
+	}
+	return pp
+}
+
+// pooledParser wraps a parser so that it is freed if the pool drops it
+func pooledParser(p Parser) *Parser {
+	pp := &p
+	runtime.SetFinalizer(pp, func(p *Parser) { p.Close() })
+	return pp
+}
+
+// Get takes a parser from the pool, return it with Put when finished
+// rather than calling Close
+func (pp *ParserPool) Get() Parser {
+	p := pp.pool.Get().(*Parser)
+	runtime.SetFinalizer(p, nil)
+	return *p
+}
+
+// Put returns a parser to the pool
+// The parser is finished first, so input written but not yet returned
+// by Tree is discarded rather than parsed with the next document
+func (pp *ParserPool) Put(p Parser) {
+	p.Tree().Close()
+	pp.pool.Put(pooledParser(p))
+}
+
+// ParseBytes parses b with a parser from the pool
+// Like Parser.ParseBytes it drops the error of a limit being exceeded,
+// use ParseBytesChecked to see it
+func (pp *ParserPool) ParseBytes(b []byte) Node {
+	p := pp.Get()
+	defer pp.Put(p)
+	return p.ParseBytes(b)
+}
+
+// ParseBytesChecked parses b with a parser from the pool, returning
+// the error of a limit being exceeded as Parser.ParseBytesChecked does
+func (pp *ParserPool) ParseBytesChecked(b []byte) (Node, error) {
+	p := pp.Get()
+	defer pp.Put(p)
+	return p.ParseBytesChecked(b)
+}
@@ -0,0 +1,92 @@
+//go:build cgo
+
+package cmark
+
+// #include <stdlib.h>
+// #include <string.h>
+// #include "cmark_go.h"
+import "C"
+import (
+	"io"
+	"unsafe"
+)
+
+// HTMLReader reads the html rendering of a node straight from the
+// buffer cmark renders into, without copying it into a Go string
+type HTMLReader struct {
+	node Node
+	opts Opt
+	html *C.char
+	// mem allocated html, the node may be closed before the reader
+	mem *C.cmark_mem
+	// buf is the rendered html, backed by html
+	buf    []byte
+	off    int
+	closed bool
+}
+
+// NewHTMLReader returns a reader of the html rendering of n,
+// rendering happens on the first Read or WriteTo
+// Call Close when finished to free the rendered html
+func NewHTMLReader(n Node, opts Opt) *HTMLReader {
+	return &HTMLReader{node: n, opts: opts}
+}
+
+func (r *HTMLReader) render() {
+	if r.html != nil || r.closed {
+		return
+	}
+	r.mem = C.cmark_node_mem(r.node.node)
+	r.html = renderHTML(r.node.node, C.int(r.opts))
+	r.buf = unsafe.Slice((*byte)(unsafe.Pointer(r.html)), C.strlen(r.html))
+}
+
+// Read implements io.Reader
+func (r *HTMLReader) Read(p []byte) (n int, err error) {
+	r.render()
+	if r.off >= len(r.buf) {
+		return 0, io.EOF
+	}
+	n = copy(p, r.buf[r.off:])
+	r.off += n
+	return n, nil
+}
+
+// WriteTo implements io.WriterTo, writing the unread html to w
+func (r *HTMLReader) WriteTo(w io.Writer) (n int64, err error) {
+	r.render()
+	m, err := w.Write(r.buf[r.off:])
+	r.off += m
+	return int64(m), err
+}
+
+// Close frees the rendered html, it does not close the node
+func (r *HTMLReader) Close() error {
+	if r.html != nil {
+		C.go_cmark_mem_free(r.mem, unsafe.Pointer(r.html))
+		r.html = nil
+		r.buf = nil
+	}
+	r.closed = true
+	return nil
+}
+
+// WriteTo implements io.WriterTo, writing the html rendering of the
+// node with OptDefault to w
+// Use NodeRenderer to render with other options
+func (n Node) WriteTo(w io.Writer) (int64, error) {
+	return NodeRenderer{Node: n, Options: OptDefault}.WriteTo(w)
+}
+
+// NodeRenderer writes the html rendering of Node with Options
+type NodeRenderer struct {
+	Node    Node
+	Options Opt
+}
+
+// WriteTo implements io.WriterTo
+func (r NodeRenderer) WriteTo(w io.Writer) (int64, error) {
+	hr := NewHTMLReader(r.Node, r.Options)
+	defer hr.Close()
+	return hr.WriteTo(w)
+}
@@ -0,0 +1,96 @@
+//go:build cgo
+
+package cmark
+
+import (
+	"html/template"
+	"testing"
+)
+
+// renderTest is a markdown input and the output expected of a renderer
+type renderTest struct {
+	md, want string
+}
+
+func testRenderer(t *testing.T, name string, render func(Node) string, tests []renderTest) {
+	t.Helper()
+	p := NewParser(OptDefault)
+	defer p.Close()
+	for _, tt := range tests {
+		doc := p.ParseString(tt.md)
+		if got := render(doc); got != tt.want {
+			t.Errorf("%s(%q) =\n%q\nwant\n%q", name, tt.md, got, tt.want)
+		}
+		doc.Close()
+	}
+}
+
+func TestRenderRST(t *testing.T) {
+	testRenderer(t, "RenderRST", func(n Node) string { return n.RenderRST(OptDefault) }, []renderTest{
+		{
+			"# Title\n\nSome *emph* and **strong *nested*** text.\n",
+			"Title\n=====\n\nSome *emph* and **strong nested** text.\n",
+		},
+		{
+			"- a\n- b\n\n> quote\n\n```go\nx := 1\n```\n\n*see [docs](http://x.io)*\n",
+			"- a\n- b\n\n    quote\n\n.. code-block:: go\n\n   x := 1\n\n*see docs*\n",
+		},
+	})
+}
+
+func TestRenderANSI(t *testing.T) {
+	testRenderer(t, "RenderANSI", func(n Node) string { return n.RenderANSI(OptDefault, 0) }, []renderTest{
+		{
+			"# A **b** c\n\n> *x **y** z*\n",
+			ansiBold + "A " + ansiItalic + "b" + ansiItalicOff + " c" + ansiBoldOff + "\n\n" +
+				"│ " + ansiItalic + "x " + ansiBold + "y" + ansiBoldOff + " z" + ansiItalicOff + "\n",
+		},
+		{
+			"1. one\n2. [two](/t) `x`\n",
+			"1. one\n2. " + ansiUnder + "two" + ansiUnderOff + " (/t) " + ansiRev + "x" + ansiRevOff + "\n",
+		},
+	})
+}
+
+func TestRenderAsciiDoc(t *testing.T) {
+	testRenderer(t, "RenderAsciiDoc", func(n Node) string { return n.RenderAsciiDoc(OptDefault) }, []renderTest{
+		{
+			"# T\n\nA *b* **c** `d` [e](/f)\n\n- x\n- y\n\n> q\n",
+			"== T\n\nA _b_ *c* `d` link:/f[e]\n\n* x\n* y\n\n____\nq\n____\n",
+		},
+		{
+			"```go\nx\n```\n\n1. a\n\n   b\n",
+			"[source,go]\n----\nx\n----\n\n. a\n+\nb\n",
+		},
+	})
+}
+
+func TestRenderMediaWiki(t *testing.T) {
+	testRenderer(t, "RenderMediaWiki", func(n Node) string { return n.RenderMediaWiki(OptDefault) }, []renderTest{
+		{
+			"## H\n\nA *b* **c** `<d>` [e](http://f.io) [g](Page)\n\n1. x\n   - y\n",
+			"=== H ===\n\nA ''b'' '''c''' <code>&lt;d&gt;</code> [http://f.io e] [[Page|g]]\n\n# x\n#* y\n",
+		},
+		{
+			"- a\n  b\n\n  c\n",
+			"* a b\n*: c\n",
+		},
+	})
+}
+
+func TestRenderToTemplate(t *testing.T) {
+	p := NewParser(OptDefault)
+	doc := p.ParseString("<b>x</b>\n")
+	p.Close()
+	defer doc.Close()
+	tmpl := template.Must(template.New("page").Parse("<title>{{.Title}}</title>{{.Content}}"))
+	got, err := doc.RenderToTemplate(tmpl, map[string]string{"Title": "T", "Content": "lost"}, optUnsafe)
+	if want := "<title>T</title><p><b>x</b></p>\n"; err != nil || got != want {
+		t.Errorf("RenderToTemplate with a map = %q, %v, want %q", got, err, want)
+	}
+	tmpl = template.Must(template.New("page").Parse("<title>{{.Data.Title}}</title>{{.Content}}"))
+	got, err = doc.RenderToTemplate(tmpl, struct{ Title string }{"T"}, optUnsafe)
+	if want := "<title>T</title><p><b>x</b></p>\n"; err != nil || got != want {
+		t.Errorf("RenderToTemplate with a struct = %q, %v, want %q", got, err, want)
+	}
+}
@@ -0,0 +1,155 @@
+package cmark
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// rstUnderlines are the heading underline characters by level
+const rstUnderlines = "=-~^\"'"
+
+// rstEscaper escapes the characters which start reST inline markup
+var rstEscaper = strings.NewReplacer(`\`, `\\`, "*", `\*`, "`", "\\`", "|", `\|`)
+
+// RenderRST renders the document as reStructuredText: headings are
+// underlined with "=", "-", "~" and "^" by level, code blocks become
+// code-block directives, strong emphasis is **text**, emphasis is
+// *text* and links are `text <url>`_
+// reST cannot nest inline markup, so markup inside emphasis and links
+// is kept as text, and images are rendered as links
+func (n Node) RenderRST(options Opt) string {
+	r := rstRenderer{opts: options}
+	Walk(n, func(n Node, ev Event) error {
+		r.event(n, ev)
+		return nil
+	})
+	return r.out.String()
+}
+
+type rstRenderer struct {
+	blockWriter
+	opts Opt
+	// inline is the content of the current paragraph or heading
+	inline strings.Builder
+	// markup is the number of open emphasis, strong and link nodes,
+	// only the outermost is written as markup
+	markup int
+}
+
+func (r *rstRenderer) event(n Node, ev Event) {
+	typ, _ := n.Type()
+	enter := ev == EventEnter
+	switch typ {
+	case NodeParagraph:
+		if enter {
+			r.startBlock()
+		} else {
+			r.flush()
+		}
+	case NodeHeading:
+		if enter {
+			r.startBlock()
+			return
+		}
+		level, _ := n.HeadingLevel()
+		if level < 1 {
+			level = 1
+		} else if level > len(rstUnderlines) {
+			level = len(rstUnderlines)
+		}
+		text := strings.ReplaceAll(r.inline.String(), "\n", " ")
+		r.inline.Reset()
+		r.writeLine(text)
+		r.writeLine(strings.Repeat(rstUnderlines[level-1:level], utf8.RuneCountInString(text)))
+		r.blank = true
+	case NodeBlockQuote:
+		r.quote(enter, "    ")
+	case NodeList:
+		r.list(n, enter)
+	case NodeItem:
+		r.item(enter, "- ")
+	case NodeCodeBlock:
+		r.startBlock()
+		if info := strings.Fields(n.FenceInfo()); len(info) > 0 {
+			r.writeLine(".. code-block:: " + info[0])
+		} else {
+			r.writeLine("::")
+		}
+		r.writeIndented(n.Literal())
+	case NodeHTMLBlock:
+		r.startBlock()
+		r.writeLine(".. raw:: html")
+		r.writeIndented(n.Literal())
+	case NodeThematicBreak:
+		r.startBlock()
+		r.writeLine("----")
+		r.blank = true
+	case NodeText:
+		r.inline.WriteString(rstEscaper.Replace(n.Literal()))
+	case NodeHTMLInline:
+		r.inline.WriteString(n.Literal())
+	case NodeCode:
+		if r.markup > 0 {
+			r.inline.WriteString(rstEscaper.Replace(n.Literal()))
+		} else {
+			r.inline.WriteString("``" + n.Literal() + "``")
+		}
+	case NodeSoftBreak:
+		if r.opts&OptNoBreaks != 0 {
+			r.inline.WriteString(" ")
+		} else {
+			r.inline.WriteString("\n")
+		}
+	case NodeLineBreak:
+		r.inline.WriteString("\n")
+	case NodeEmph, NodeStrong, NodeLink, NodeImage:
+		r.markupEvent(n, typ, enter)
+	}
+}
+
+// markupEvent writes the markup of the outermost emphasis, strong or
+// link node, the markup of nodes inside it is dropped
+func (r *rstRenderer) markupEvent(n Node, typ NodeType, enter bool) {
+	if !enter {
+		r.markup--
+	}
+	if r.markup == 0 {
+		switch {
+		case typ == NodeEmph:
+			r.inline.WriteString("*")
+		case typ == NodeStrong:
+			r.inline.WriteString("**")
+		case enter:
+			r.inline.WriteString("`")
+		default:
+			r.inline.WriteString(" <" + n.URL() + ">`_")
+		}
+	}
+	if enter {
+		r.markup++
+	}
+}
+
+// flush writes the current inline content
+func (r *rstRenderer) flush() {
+	text := r.inline.String()
+	r.inline.Reset()
+	for _, line := range strings.Split(text, "\n") {
+		r.writeLine(line)
+	}
+	r.blank = true
+}
+
+// writeIndented writes the body of a directive, after a blank line
+// and indented by three spaces
+func (r *rstRenderer) writeIndented(body string) {
+	r.writeLine("")
+	for _, line := range strings.Split(strings.TrimSuffix(body, "\n"), "\n") {
+		if line == "" {
+			r.writeLine("")
+		} else {
+			r.writeLine("   " + line)
+		}
+	}
+	r.blank = true
+}
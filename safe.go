@@ -0,0 +1,565 @@
+package cmark
+
+import (
+	"context"
+	"html/template"
+	"io"
+	"unsafe"
+)
+
+// SafeNode wraps a Node so that calling a method on a nil node panics
+// with a message naming the method, and the navigation method which
+// returned the nil node, rather than crashing inside cmark
+//
+// Every method of Node is wrapped and checked, which costs a
+// comparison per call, and navigation methods return SafeNodes
+type SafeNode struct {
+	node Node
+	// from is the method which returned this node
+	from string
+}
+
+// Safe wraps n in a SafeNode
+func Safe(n Node) SafeNode {
+	return SafeNode{node: n}
+}
+
+// Node returns the wrapped node, to pass it to functions taking a Node
+func (s SafeNode) Node() Node {
+	return s.node
+}
+
+// IsNil returns true if the wrapped node is nil, it never panics
+func (s SafeNode) IsNil() bool {
+	return s.node.IsNil()
+}
+
+func (s SafeNode) check(method string) {
+	if !s.IsNil() {
+		return
+	}
+	if s.from == "" {
+		panic("cmark: called " + method + " on nil node")
+	}
+	panic("cmark: called " + method + " on nil node (returned by " + s.from + ")")
+}
+
+func (s SafeNode) Next() SafeNode {
+	s.check("Next")
+	return SafeNode{node: s.node.Next(), from: "Next"}
+}
+
+func (s SafeNode) Previous() SafeNode {
+	s.check("Previous")
+	return SafeNode{node: s.node.Previous(), from: "Previous"}
+}
+
+func (s SafeNode) Parent() SafeNode {
+	s.check("Parent")
+	return SafeNode{node: s.node.Parent(), from: "Parent"}
+}
+
+func (s SafeNode) FirstChild() SafeNode {
+	s.check("FirstChild")
+	return SafeNode{node: s.node.FirstChild(), from: "FirstChild"}
+}
+
+func (s SafeNode) LastChild() SafeNode {
+	s.check("LastChild")
+	return SafeNode{node: s.node.LastChild(), from: "LastChild"}
+}
+
+func (s SafeNode) HasChildren() bool {
+	s.check("HasChildren")
+	return s.node.HasChildren()
+}
+
+func (s SafeNode) FirstLeaf() SafeNode {
+	s.check("FirstLeaf")
+	return SafeNode{node: s.node.FirstLeaf(), from: "FirstLeaf"}
+}
+
+func (s SafeNode) LastLeaf() SafeNode {
+	s.check("LastLeaf")
+	return SafeNode{node: s.node.LastLeaf(), from: "LastLeaf"}
+}
+
+func (s SafeNode) Depth() int {
+	s.check("Depth")
+	return s.node.Depth()
+}
+
+func (s SafeNode) SiblingsBefore() []Node {
+	s.check("SiblingsBefore")
+	return s.node.SiblingsBefore()
+}
+
+func (s SafeNode) SiblingsAfter() []Node {
+	s.check("SiblingsAfter")
+	return s.node.SiblingsAfter()
+}
+
+func (s SafeNode) Siblings() []Node {
+	s.check("Siblings")
+	return s.node.Siblings()
+}
+
+func (s SafeNode) UserData() unsafe.Pointer {
+	s.check("UserData")
+	return s.node.UserData()
+}
+
+func (s SafeNode) SetUserData(u unsafe.Pointer) {
+	s.check("SetUserData")
+	s.node.SetUserData(u)
+}
+
+func (s SafeNode) GoData() interface{} {
+	s.check("GoData")
+	return s.node.GoData()
+}
+
+func (s SafeNode) SetGoData(v interface{}) {
+	s.check("SetGoData")
+	s.node.SetGoData(v)
+}
+
+func (s SafeNode) Type() (NodeType, error) {
+	s.check("Type")
+	return s.node.Type()
+}
+
+func (s SafeNode) IsBlock() bool {
+	s.check("IsBlock")
+	return s.node.IsBlock()
+}
+
+func (s SafeNode) IsInline() bool {
+	s.check("IsInline")
+	return s.node.IsInline()
+}
+
+func (s SafeNode) IsLeaf() bool {
+	s.check("IsLeaf")
+	return s.node.IsLeaf()
+}
+
+func (s SafeNode) TypeString() string {
+	s.check("TypeString")
+	return s.node.TypeString()
+}
+
+func (s SafeNode) Literal() string {
+	s.check("Literal")
+	return s.node.Literal()
+}
+
+func (s SafeNode) SetLiteral(lit string) {
+	s.check("SetLiteral")
+	s.node.SetLiteral(lit)
+}
+
+func (s SafeNode) SetLiteralBytes(b []byte) {
+	s.check("SetLiteralBytes")
+	s.node.SetLiteralBytes(b)
+}
+
+func (s SafeNode) HeadingLevel() (int, error) {
+	s.check("HeadingLevel")
+	return s.node.HeadingLevel()
+}
+
+func (s SafeNode) SetHeadingText(text string) error {
+	s.check("SetHeadingText")
+	return s.node.SetHeadingText(text)
+}
+
+func (s SafeNode) ListType() (ListType, error) {
+	s.check("ListType")
+	return s.node.ListType()
+}
+
+func (s SafeNode) SetListType(typ ListType) error {
+	s.check("SetListType")
+	return s.node.SetListType(typ)
+}
+
+func (s SafeNode) ListDelim() (ListDelim, error) {
+	s.check("ListDelim")
+	return s.node.ListDelim()
+}
+
+func (s SafeNode) SetListDelim(typ ListDelim) error {
+	s.check("SetListDelim")
+	return s.node.SetListDelim(typ)
+}
+
+func (s SafeNode) ListStart() (int, error) {
+	s.check("ListStart")
+	return s.node.ListStart()
+}
+
+func (s SafeNode) SetListStart(start int) error {
+	s.check("SetListStart")
+	return s.node.SetListStart(start)
+}
+
+func (s SafeNode) TightList() bool {
+	s.check("TightList")
+	return s.node.TightList()
+}
+
+func (s SafeNode) SetTightList(tight bool) error {
+	s.check("SetTightList")
+	return s.node.SetTightList(tight)
+}
+
+func (s SafeNode) FenceInfo() string {
+	s.check("FenceInfo")
+	return s.node.FenceInfo()
+}
+
+func (s SafeNode) SetFenceInfo(fence string) error {
+	s.check("SetFenceInfo")
+	return s.node.SetFenceInfo(fence)
+}
+
+func (s SafeNode) FenceLength() int {
+	s.check("FenceLength")
+	return s.node.FenceLength()
+}
+
+func (s SafeNode) SetFenceLength(length int) error {
+	s.check("SetFenceLength")
+	return s.node.SetFenceLength(length)
+}
+
+func (s SafeNode) URL() string {
+	s.check("URL")
+	return s.node.URL()
+}
+
+func (s SafeNode) SetURL(url string) error {
+	s.check("SetURL")
+	return s.node.SetURL(url)
+}
+
+func (s SafeNode) Title() string {
+	s.check("Title")
+	return s.node.Title()
+}
+
+func (s SafeNode) SetTitle(title string) error {
+	s.check("SetTitle")
+	return s.node.SetTitle(title)
+}
+
+func (s SafeNode) OnEnter() string {
+	s.check("OnEnter")
+	return s.node.OnEnter()
+}
+
+func (s SafeNode) SetOnEnter(onEnter string) error {
+	s.check("SetOnEnter")
+	return s.node.SetOnEnter(onEnter)
+}
+
+func (s SafeNode) OnExit() string {
+	s.check("OnExit")
+	return s.node.OnExit()
+}
+
+func (s SafeNode) SetOnExit(onExit string) error {
+	s.check("SetOnExit")
+	return s.node.SetOnExit(onExit)
+}
+
+func (s SafeNode) Range() SourceRange {
+	s.check("Range")
+	return s.node.Range()
+}
+
+func (s SafeNode) StartLine() int {
+	s.check("StartLine")
+	return s.node.StartLine()
+}
+
+func (s SafeNode) StartColumn() int {
+	s.check("StartColumn")
+	return s.node.StartColumn()
+}
+
+func (s SafeNode) EndtLine() int {
+	s.check("EndtLine")
+	return s.node.EndtLine()
+}
+
+func (s SafeNode) EndColumn() int {
+	s.check("EndColumn")
+	return s.node.EndColumn()
+}
+
+func (s SafeNode) Unlink() {
+	s.check("Unlink")
+	s.node.Unlink()
+}
+
+func (s SafeNode) InsertBefore(sibling Node) error {
+	s.check("InsertBefore")
+	return s.node.InsertBefore(sibling)
+}
+
+func (s SafeNode) InsertAfter(sibling Node) error {
+	s.check("InsertAfter")
+	return s.node.InsertAfter(sibling)
+}
+
+func (s SafeNode) Replace(n Node) error {
+	s.check("Replace")
+	return s.node.Replace(n)
+}
+
+func (s SafeNode) PrependChild(c Node) error {
+	s.check("PrependChild")
+	return s.node.PrependChild(c)
+}
+
+func (s SafeNode) AppendChild(c Node) error {
+	s.check("AppendChild")
+	return s.node.AppendChild(c)
+}
+
+func (s SafeNode) ConsolidateTextNodes() {
+	s.check("ConsolidateTextNodes")
+	s.node.ConsolidateTextNodes()
+}
+
+func (s SafeNode) Normalize() error {
+	s.check("Normalize")
+	return s.node.Normalize()
+}
+
+func (s SafeNode) SetHeadingLevel(level int) error {
+	s.check("SetHeadingLevel")
+	return s.node.SetHeadingLevel(level)
+}
+
+func (s SafeNode) Close() {
+	s.check("Close")
+	s.node.Close()
+}
+
+func (s SafeNode) RenderHTML(options Opt) string {
+	s.check("RenderHTML")
+	return s.node.RenderHTML(options)
+}
+
+func (s SafeNode) RenderHTMLSafe(options Opt) string {
+	s.check("RenderHTMLSafe")
+	return s.node.RenderHTMLSafe(options)
+}
+
+func (s SafeNode) RenderHTMLFragment(options Opt) string {
+	s.check("RenderHTMLFragment")
+	return s.node.RenderHTMLFragment(options)
+}
+
+func (s SafeNode) RenderXML(options Opt) string {
+	s.check("RenderXML")
+	return s.node.RenderXML(options)
+}
+
+func (s SafeNode) RenderMan(options Opt, wrapWidth int) string {
+	s.check("RenderMan")
+	return s.node.RenderMan(options, wrapWidth)
+}
+
+func (s SafeNode) RenderLaTeX(options Opt, wrapWidth int) string {
+	s.check("RenderLaTeX")
+	return s.node.RenderLaTeX(options, wrapWidth)
+}
+
+func (s SafeNode) RenderCommonMark(options Opt, wrapWidth int) string {
+	s.check("RenderCommonMark")
+	return s.node.RenderCommonMark(options, wrapWidth)
+}
+
+func (s SafeNode) ToCommonMarkString() string {
+	s.check("ToCommonMarkString")
+	return s.node.ToCommonMarkString()
+}
+
+func (s SafeNode) ToHTMLString() string {
+	s.check("ToHTMLString")
+	return s.node.ToHTMLString()
+}
+
+func (s SafeNode) Iter() Iter {
+	s.check("Iter")
+	return s.node.Iter()
+}
+
+func (s SafeNode) ChildAt(index int) (SafeNode, error) {
+	s.check("ChildAt")
+	n, err := s.node.ChildAt(index)
+	return SafeNode{node: n, from: "ChildAt"}, err
+}
+
+func (s SafeNode) ChildCount() int {
+	s.check("ChildCount")
+	return s.node.ChildCount()
+}
+
+func (s SafeNode) IndexInParent() (int, error) {
+	s.check("IndexInParent")
+	return s.node.IndexInParent()
+}
+
+func (s SafeNode) CanContain(childType NodeType) bool {
+	s.check("CanContain")
+	return s.node.CanContain(childType)
+}
+
+func (s SafeNode) Validate() error {
+	s.check("Validate")
+	return s.node.Validate()
+}
+
+func (s SafeNode) MoveChildrenTo(dest Node) error {
+	s.check("MoveChildrenTo")
+	return s.node.MoveChildrenTo(dest)
+}
+
+func (s SafeNode) RemoveChildren() {
+	s.check("RemoveChildren")
+	s.node.RemoveChildren()
+}
+
+func (s SafeNode) SwapWith(other Node) error {
+	s.check("SwapWith")
+	return s.node.SwapWith(other)
+}
+
+func (s SafeNode) WrapChildren(parentType NodeType) (SafeNode, error) {
+	s.check("WrapChildren")
+	n, err := s.node.WrapChildren(parentType)
+	return SafeNode{node: n, from: "WrapChildren"}, err
+}
+
+func (s SafeNode) Clone() (SafeNode, error) {
+	s.check("Clone")
+	n, err := s.node.Clone()
+	return SafeNode{node: n, from: "Clone"}, err
+}
+
+func (s SafeNode) Equal(other Node) bool {
+	s.check("Equal")
+	return s.node.Equal(other)
+}
+
+func (s SafeNode) ApplyDiff(ops []DiffOp) error {
+	s.check("ApplyDiff")
+	return s.node.ApplyDiff(ops)
+}
+
+func (s SafeNode) TextContent() string {
+	s.check("TextContent")
+	return s.node.TextContent()
+}
+
+func (s SafeNode) ForEachText(fn func(n Node, text string) string) {
+	s.check("ForEachText")
+	s.node.ForEachText(fn)
+}
+
+func (s SafeNode) ReplaceText(old, new string) int {
+	s.check("ReplaceText")
+	return s.node.ReplaceText(old, new)
+}
+
+func (s SafeNode) SanitizeLinks(allowedSchemes []string) int {
+	s.check("SanitizeLinks")
+	return s.node.SanitizeLinks(allowedSchemes)
+}
+
+func (s SafeNode) MigrateToGFM() error {
+	s.check("MigrateToGFM")
+	return s.node.MigrateToGFM()
+}
+
+func (s SafeNode) SourceText(original []byte) ([]byte, error) {
+	s.check("SourceText")
+	return s.node.SourceText(original)
+}
+
+func (s SafeNode) Statistics() DocumentStats {
+	s.check("Statistics")
+	return s.node.Statistics()
+}
+
+func (s SafeNode) Events(ctx context.Context) <-chan IterEvent {
+	s.check("Events")
+	return s.node.Events(ctx)
+}
+
+func (s SafeNode) ToAST() (*ASTNode, error) {
+	s.check("ToAST")
+	return s.node.ToAST()
+}
+
+func (s SafeNode) ToMap() map[string]interface{} {
+	s.check("ToMap")
+	return s.node.ToMap()
+}
+
+func (s SafeNode) MarshalJSON() ([]byte, error) {
+	s.check("MarshalJSON")
+	return s.node.MarshalJSON()
+}
+
+func (s SafeNode) WriteTo(w io.Writer) (int64, error) {
+	s.check("WriteTo")
+	return s.node.WriteTo(w)
+}
+
+func (s SafeNode) RenderANSI(options Opt, wrapWidth int) string {
+	s.check("RenderANSI")
+	return s.node.RenderANSI(options, wrapWidth)
+}
+
+func (s SafeNode) RenderAsciiDoc(options Opt) string {
+	s.check("RenderAsciiDoc")
+	return s.node.RenderAsciiDoc(options)
+}
+
+func (s SafeNode) RenderMediaWiki(options Opt) string {
+	s.check("RenderMediaWiki")
+	return s.node.RenderMediaWiki(options)
+}
+
+func (s SafeNode) RenderRST(options Opt) string {
+	s.check("RenderRST")
+	return s.node.RenderRST(options)
+}
+
+func (s SafeNode) RenderCommonMarkNormalized(wrapWidth int) string {
+	s.check("RenderCommonMarkNormalized")
+	return s.node.RenderCommonMarkNormalized(wrapWidth)
+}
+
+func (s SafeNode) RenderHTMLWithAnchors(options Opt, slugger func(text string) string) string {
+	s.check("RenderHTMLWithAnchors")
+	return s.node.RenderHTMLWithAnchors(options, slugger)
+}
+
+func (s SafeNode) RenderToTemplate(tmpl *template.Template, data interface{}, options Opt) (string, error) {
+	s.check("RenderToTemplate")
+	return s.node.RenderToTemplate(tmpl, data, options)
+}
+
+// UnmarshalJSON points s at a new tree built from JSON produced by
+// MarshalJSON, call Close on s when finished
+func (s *SafeNode) UnmarshalJSON(data []byte) error {
+	s.from = ""
+	return s.node.UnmarshalJSON(data)
+}
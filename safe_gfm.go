@@ -0,0 +1,38 @@
+//go:build cgo && cmark_gfm
+
+package cmark
+
+func (s SafeNode) TableColumns() int {
+	s.check("TableColumns")
+	return s.node.TableColumns()
+}
+
+func (s SafeNode) TableCellAlignment(col int) TableAlignment {
+	s.check("TableCellAlignment")
+	return s.node.TableCellAlignment(col)
+}
+
+func (s SafeNode) TaskListChecked() bool {
+	s.check("TaskListChecked")
+	return s.node.TaskListChecked()
+}
+
+func (s SafeNode) SetTaskListChecked(checked bool) error {
+	s.check("SetTaskListChecked")
+	return s.node.SetTaskListChecked(checked)
+}
+
+func (s SafeNode) FootnoteLabel() string {
+	s.check("FootnoteLabel")
+	return s.node.FootnoteLabel()
+}
+
+func (s SafeNode) SetFootnoteLabel(label string) error {
+	s.check("SetFootnoteLabel")
+	return s.node.SetFootnoteLabel(label)
+}
+
+func (s SafeNode) RenderPlainText(options Opt, wrapWidth int) string {
+	s.check("RenderPlainText")
+	return s.node.RenderPlainText(options, wrapWidth)
+}
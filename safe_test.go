@@ -0,0 +1,30 @@
+package cmark
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSafeNodePanics(t *testing.T) {
+	calls := map[string]func(s SafeNode){
+		"ChildCount":  func(s SafeNode) { s.ChildCount() },
+		"TextContent": func(s SafeNode) { s.TextContent() },
+		"Clone":       func(s SafeNode) { s.Clone() },
+		"RenderRST":   func(s SafeNode) { s.RenderRST(OptDefault) },
+		"MarshalJSON": func(s SafeNode) { s.MarshalJSON() },
+	}
+	for method, call := range calls {
+		func() {
+			defer func() {
+				msg, _ := recover().(string)
+				if !strings.Contains(msg, "called "+method+" on nil node") {
+					t.Errorf("%s on a nil SafeNode panicked with %q", method, msg)
+				}
+			}()
+			call(Safe(Node{}))
+		}()
+	}
+	if !Safe(Node{}).IsNil() {
+		t.Error("IsNil is false for a nil node")
+	}
+}
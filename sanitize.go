@@ -0,0 +1,215 @@
+package cmark
+
+import (
+	"html"
+	"strings"
+)
+
+// sanitizeAttrs are the only attributes kept by SanitizeHTML
+var sanitizeAttrs = map[string]bool{
+	"href":  true,
+	"src":   true,
+	"alt":   true,
+	"title": true,
+	"class": true,
+}
+
+// sanitizeSchemes are the URL schemes SanitizeHTML keeps in href and
+// src attributes, along with relative URLs
+var sanitizeSchemes = map[string]bool{
+	"http":   true,
+	"https":  true,
+	"mailto": true,
+}
+
+// sanitizeDropContent are tags whose content is removed with them
+// when they are not allowed
+var sanitizeDropContent = map[string]bool{
+	"script": true,
+	"style":  true,
+}
+
+// SanitizeHTML removes every tag from s whose name is not in
+// allowedTags, along with the content of script and style tags
+// Allowed tags keep only their href, src, alt, title and class
+// attributes, and href and src are dropped unless they are relative
+// or use http, https or mailto
+// Comments and other markup declarations are removed
+//
+// This is meant for raw html from markdown, before inserting it into
+// the tree or when post-processing rendered html
+func SanitizeHTML(s string, allowedTags []string) string {
+	allowed := make(map[string]bool, len(allowedTags))
+	for _, t := range allowedTags {
+		allowed[strings.ToLower(t)] = true
+	}
+	var b strings.Builder
+	for len(s) > 0 {
+		i := strings.IndexByte(s, '<')
+		if i < 0 {
+			b.WriteString(s)
+			break
+		}
+		b.WriteString(s[:i])
+		s = s[i:]
+
+		if strings.HasPrefix(s, "<!--") {
+			end := strings.Index(s[4:], "-->")
+			if end < 0 {
+				break
+			}
+			s = s[4+end+3:]
+			continue
+		}
+		if strings.HasPrefix(s, "<!") || strings.HasPrefix(s, "<?") {
+			end := strings.IndexByte(s, '>')
+			if end < 0 {
+				break
+			}
+			s = s[end+1:]
+			continue
+		}
+
+		t, n := parseTag(s)
+		if n == 0 {
+			b.WriteString("&lt;")
+			s = s[1:]
+			continue
+		}
+		s = s[n:]
+		switch {
+		case allowed[t.name]:
+			b.WriteString(t.String())
+		case sanitizeDropContent[t.name] && !t.closing && !t.selfClosing:
+			end := strings.Index(strings.ToLower(s), "</"+t.name)
+			if end < 0 {
+				return b.String()
+			}
+			s = s[end:]
+		}
+	}
+	return b.String()
+}
+
+type htmlAttr struct {
+	name, value string
+}
+
+type htmlTag struct {
+	name        string
+	closing     bool
+	selfClosing bool
+	attrs       []htmlAttr
+}
+
+// String writes the tag with only the allowed attributes
+func (t htmlTag) String() string {
+	var b strings.Builder
+	b.WriteByte('<')
+	if t.closing {
+		b.WriteByte('/')
+	}
+	b.WriteString(t.name)
+	for _, a := range t.attrs {
+		if !sanitizeAttrs[a.name] {
+			continue
+		}
+		if (a.name == "href" || a.name == "src") && unsafeURL(a.value) {
+			continue
+		}
+		b.WriteString(" " + a.name + `="` + html.EscapeString(html.UnescapeString(a.value)) + `"`)
+	}
+	if t.selfClosing {
+		b.WriteString(" /")
+	}
+	b.WriteByte('>')
+	return b.String()
+}
+
+// unsafeURL reports whether url, an attribute value as written, has a
+// scheme not in sanitizeSchemes once its character references are
+// decoded and the whitespace and control characters browsers skip
+// are removed
+func unsafeURL(url string) bool {
+	url = strings.Map(func(r rune) rune {
+		if r <= ' ' || r == 0x7f {
+			return -1
+		}
+		return r
+	}, html.UnescapeString(url))
+	scheme := urlScheme(url)
+	return scheme != "" && !sanitizeSchemes[scheme]
+}
+
+func isTagNameByte(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-'
+}
+
+func isHTMLSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f'
+}
+
+// parseTag parses the tag at the start of s, returning the number of
+// bytes consumed or 0 if s does not start with a tag
+func parseTag(s string) (htmlTag, int) {
+	var t htmlTag
+	i := 1
+	if i < len(s) && s[i] == '/' {
+		t.closing = true
+		i++
+	}
+	start := i
+	for i < len(s) && isTagNameByte(s[i]) {
+		i++
+	}
+	if i == start {
+		return t, 0
+	}
+	t.name = strings.ToLower(s[start:i])
+	for i < len(s) {
+		for i < len(s) && isHTMLSpace(s[i]) {
+			i++
+		}
+		if i >= len(s) {
+			break
+		}
+		switch s[i] {
+		case '>':
+			return t, i + 1
+		case '/':
+			t.selfClosing = true
+			i++
+			continue
+		}
+		start := i
+		for i < len(s) && !isHTMLSpace(s[i]) && s[i] != '=' && s[i] != '>' && s[i] != '/' {
+			i++
+		}
+		a := htmlAttr{name: strings.ToLower(s[start:i])}
+		for i < len(s) && isHTMLSpace(s[i]) {
+			i++
+		}
+		if i < len(s) && s[i] == '=' {
+			i++
+			for i < len(s) && isHTMLSpace(s[i]) {
+				i++
+			}
+			if i < len(s) && (s[i] == '"' || s[i] == '\'') {
+				end := strings.IndexByte(s[i+1:], s[i])
+				if end < 0 {
+					return t, 0
+				}
+				a.value = s[i+1 : i+1+end]
+				i += end + 2
+			} else {
+				start := i
+				for i < len(s) && !isHTMLSpace(s[i]) && s[i] != '>' {
+					i++
+				}
+				a.value = s[start:i]
+			}
+		}
+		t.attrs = append(t.attrs, a)
+	}
+	return t, 0
+}
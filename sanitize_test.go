@@ -0,0 +1,41 @@
+package cmark
+
+import "testing"
+
+func TestSanitizeHTMLURLs(t *testing.T) {
+	allowed := []string{"a", "img"}
+	tests := []struct {
+		in, want string
+	}{
+		{`<a href="https://example.com/?a=1&amp;b=2">x</a>`, `<a href="https://example.com/?a=1&amp;b=2">x</a>`},
+		{`<a href="/docs#top">x</a>`, `<a href="/docs#top">x</a>`},
+		{`<a href="mailto:me@example.com">x</a>`, `<a href="mailto:me@example.com">x</a>`},
+		{`<a href="javascript:alert(1)">x</a>`, `<a>x</a>`},
+		{`<a href="JavaScript:alert(1)">x</a>`, `<a>x</a>`},
+		{`<a href="&#106;avascript:alert(1)">x</a>`, `<a>x</a>`},
+		{`<a href="&#x6A;avascript:alert(1)">x</a>`, `<a>x</a>`},
+		{`<a href="java&#x09;script:alert(1)">x</a>`, `<a>x</a>`},
+		{`<a href="java&Tab;script:alert(1)">x</a>`, `<a>x</a>`},
+		{"<a href=\"jav\tascript:alert(1)\">x</a>", `<a>x</a>`},
+		{"<a href=\"jav\nascript:alert(1)\">x</a>", `<a>x</a>`},
+		{"<a href=\" \x01javascript:alert(1)\">x</a>", `<a>x</a>`},
+		{`<a href="javascript&colon;alert(1)">x</a>`, `<a>x</a>`},
+		{`<a href="vbscript:msgbox(1)">x</a>`, `<a>x</a>`},
+		{`<img src="data:text/html;base64,PHNjcmlwdD4=">`, `<img>`},
+		{`<a href="x" onclick="alert(1)">x</a>`, `<a href="x">x</a>`},
+		{`<a title="&quot;><script>">x</a>`, `<a title="&#34;&gt;&lt;script&gt;">x</a>`},
+	}
+	for _, tt := range tests {
+		if got := SanitizeHTML(tt.in, allowed); got != tt.want {
+			t.Errorf("SanitizeHTML(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestSanitizeHTMLTags(t *testing.T) {
+	in := `<p>hi <em>there</em><script>alert(1)</script><!-- c --><strong>!</strong></p>`
+	want := `hi <em>there</em><strong>!</strong>`
+	if got := SanitizeHTML(in, []string{"em", "strong"}); got != want {
+		t.Errorf("SanitizeHTML = %q, want %q", got, want)
+	}
+}
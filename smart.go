@@ -0,0 +1,93 @@
+package cmark
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// SmartPunctuationRewrite applies smart punctuation to every text node
+// under root, like OptSmart does during parsing:
+// "---" becomes an em dash, "--" an en dash and straight quotes
+// become curly quotes
+// Code spans and code blocks are not text nodes and are left untouched
+//
+// Returns the number of replacements made
+func SmartPunctuationRewrite(root Node) int {
+	count := 0
+	for _, text := range collectNodes(root, NodeText) {
+		lit, n := smartPunctuation(text.Literal(), opensAfter(text))
+		if n > 0 {
+			text.SetLiteral(lit)
+			count += n
+		}
+	}
+	return count
+}
+
+// opensAfter reports whether a quote at the start of text should be an
+// opening quote, as at the start of a block or after whitespace or an
+// opening bracket
+// cmark splits text nodes at brackets and emphasis delimiters, so the
+// character before the quote may be in an earlier node, or before the
+// emphasis or link holding text
+func opensAfter(text Node) bool {
+	for n := text; ; n = n.Parent() {
+		for prev := n.Previous(); prev.node != nil; prev = prev.Previous() {
+			leaf := prev.LastLeaf()
+			switch typ, _ := leaf.Type(); typ {
+			case NodeSoftBreak, NodeLineBreak:
+				return true
+			case NodeText, NodeCode, NodeHTMLInline:
+				if lit := leaf.Literal(); lit != "" {
+					r, _ := utf8.DecodeLastRuneInString(lit)
+					return opensQuote(r)
+				}
+			}
+		}
+		if !n.Parent().IsInline() {
+			return true
+		}
+	}
+}
+
+// opensQuote reports whether a quote following prev is an opening quote
+func opensQuote(prev rune) bool {
+	return unicode.IsSpace(prev) || strings.ContainsRune("([{—–", prev)
+}
+
+// smartPunctuation rewrites dashes and quotes in s, open is whether
+// a quote at the start of s is an opening quote
+func smartPunctuation(s string, open bool) (string, int) {
+	var b strings.Builder
+	count := 0
+	runes := []rune(s)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		opening := open
+		if i > 0 {
+			opening = opensQuote(runes[i-1])
+		}
+		switch {
+		case r == '-' && i+2 < len(runes) && runes[i+1] == '-' && runes[i+2] == '-':
+			b.WriteRune('—')
+			i += 2
+		case r == '-' && i+1 < len(runes) && runes[i+1] == '-':
+			b.WriteRune('–')
+			i++
+		case r == '"' && opening:
+			b.WriteRune('“')
+		case r == '"':
+			b.WriteRune('”')
+		case r == '\'' && opening:
+			b.WriteRune('‘')
+		case r == '\'':
+			b.WriteRune('’')
+		default:
+			b.WriteRune(r)
+			continue
+		}
+		count++
+	}
+	return b.String(), count
+}
@@ -0,0 +1,37 @@
+package cmark
+
+import "errors"
+
+// ErrNoSourcePos is returned when a node has no source position,
+// parse with OptSourcePos to record positions
+var ErrNoSourcePos = errors.New("Node has no source position")
+
+// SourceText returns the bytes of original, the input the node was
+// parsed from, which the node spans, lines may end in "\n" or "\r\n"
+// For block nodes cmark's range includes any container markers, e.g.
+// the "> " of a block quote, on lines after the first
+func (n Node) SourceText(original []byte) ([]byte, error) {
+	r := n.Range()
+	if r.StartLine == 0 {
+		return nil, ErrNoSourcePos
+	}
+	// lines[i] is the offset at which line i+1 starts
+	lines := []int{0}
+	for i, b := range original {
+		if b == '\n' {
+			lines = append(lines, i+1)
+		}
+	}
+	if r.EndLine < r.StartLine || r.EndLine > len(lines) {
+		return nil, n.nodeError("SourceText", "source position is outside the input")
+	}
+	start := lines[r.StartLine-1] + r.StartColumn - 1
+	end := lines[r.EndLine-1] + r.EndColumn
+	if end > len(original) {
+		end = len(original)
+	}
+	if start < 0 || start > end {
+		return nil, n.nodeError("SourceText", "source position is outside the input")
+	}
+	return original[start:end], nil
+}
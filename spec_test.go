@@ -0,0 +1,95 @@
+//go:build cgo && !cmark_gfm
+
+package cmark
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// The spec examples are not part of the repository, fetch the spec of
+// the linked cmark version with go generate, or point CMARK_SPEC at a
+// copy of cmark's test/spec.txt
+// go generate expands $DOLLAR to $ for the shell
+//go:generate sh -c "v=$DOLLAR(pkg-config --modversion libcmark) && curl -fsSL --create-dirs -o testdata/spec-$DOLLAR{v}.txt https://raw.githubusercontent.com/commonmark/cmark/$DOLLAR{v}/test/spec.txt"
+
+type specExample struct {
+	Markdown  string
+	HTML      string
+	Example   int
+	StartLine int
+	Section   string
+}
+
+// specFence opens and closes the examples of spec.txt
+const specFence = "````````````````````````````````"
+
+// parseSpec reads the examples of spec.txt, in which tabs are
+// written as →
+func parseSpec(data []byte) []specExample {
+	var examples []specExample
+	var ex *specExample
+	var section string
+	var md, html strings.Builder
+	inHTML := false
+	s := bufio.NewScanner(bytes.NewReader(data))
+	for line := 1; s.Scan(); line++ {
+		text := strings.ReplaceAll(s.Text(), "→", "\t")
+		switch {
+		case ex == nil && text == specFence+" example":
+			ex = &specExample{Example: len(examples) + 1, StartLine: line, Section: section}
+			md.Reset()
+			html.Reset()
+			inHTML = false
+		case ex == nil:
+			if strings.HasPrefix(text, "#") {
+				section = strings.TrimSpace(strings.TrimLeft(text, "#"))
+			}
+		case text == specFence:
+			ex.Markdown, ex.HTML = md.String(), html.String()
+			examples = append(examples, *ex)
+			ex = nil
+		case text == "." && !inHTML:
+			inHTML = true
+		case inHTML:
+			html.WriteString(text + "\n")
+		default:
+			md.WriteString(text + "\n")
+		}
+	}
+	return examples
+}
+
+func TestCommonMarkSpec(t *testing.T) {
+	path := os.Getenv("CMARK_SPEC")
+	if path == "" {
+		path = filepath.Join("testdata", "spec-"+cmarkVersion()+".txt")
+	}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) && os.Getenv("CI") == "" {
+		t.Skipf("%s not found, run go generate to fetch it", path)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	examples := parseSpec(data)
+	if len(examples) == 0 {
+		t.Fatalf("%s has no examples", path)
+	}
+	p := NewParser(OptDefault)
+	defer p.Close()
+	// the examples include raw html
+	for _, ex := range examples {
+		doc := p.ParseString(ex.Markdown)
+		html := doc.RenderHTML(optUnsafe)
+		doc.Close()
+		if html != ex.HTML {
+			t.Errorf("Example %d (%s, line %d)\ninput:\n%s\nexpected:\n%s\nactual:\n%s",
+				ex.Example, ex.Section, ex.StartLine, ex.Markdown, ex.HTML, html)
+		}
+	}
+}
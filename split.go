@@ -0,0 +1,88 @@
+package cmark
+
+// Clone returns a deep copy of the subtree of this node which is not
+// linked into any tree, call Close when finished
+// Source positions and user data are not copied
+func (n Node) Clone() (Node, error) {
+	typ, err := n.Type()
+	if err != nil {
+		return Node{}, err
+	}
+	c := newNodeLike(n, typ)
+	err = c.setJSONAttributes(typ, jsonNode{nodeAttributes: attributesOf(n)})
+	if err == nil {
+		err = copyExtensionAttributes(c, n, typ)
+	}
+	if err != nil {
+		c.Close()
+		return Node{}, err
+	}
+	for child := n.FirstChild(); child.node != nil; child = child.Next() {
+		cc, err := child.Clone()
+		if err == nil {
+			err = c.AppendChild(cc)
+			if err != nil {
+				cc.Close()
+			}
+		}
+		if err != nil {
+			c.Close()
+			return Node{}, err
+		}
+	}
+	return c, nil
+}
+
+// SplitAtHeadings splits the top level blocks of root into sections,
+// each new section starts at a heading of the given level
+// Any blocks before the first such heading form the first section
+//
+// Each section is a new document holding copies of the blocks,
+// call Close on each of them when finished
+// If a block cannot be copied no sections are returned, with the error
+func SplitAtHeadings(root Node, level int) ([]Node, error) {
+	var sections []Node
+	var cur Node
+	for c := root.FirstChild(); c.node != nil; c = c.Next() {
+		if l, err := c.HeadingLevel(); (err == nil && l == level) || cur.node == nil {
+			cur = NewNode(NodeDocument)
+			sections = append(sections, cur)
+		}
+		clone, err := c.Clone()
+		if err == nil {
+			err = cur.AppendChild(clone)
+			if err != nil {
+				clone.Close()
+			}
+		}
+		if err != nil {
+			for _, s := range sections {
+				s.Close()
+			}
+			return nil, err
+		}
+	}
+	return sections, nil
+}
+
+// MergeDocuments returns a new document holding copies of the children
+// of each document in order, call Close on it when finished
+func MergeDocuments(docs ...Node) (Node, error) {
+	merged := NewNode(NodeDocument)
+	for _, doc := range docs {
+		for c := doc.FirstChild(); c.node != nil; c = c.Next() {
+			clone, err := c.Clone()
+			if err == nil {
+				err = merged.AppendChild(clone)
+				if err != nil {
+					clone.Close()
+				}
+			}
+			if err != nil {
+				merged.Close()
+				return Node{}, err
+			}
+		}
+	}
+	return merged, nil
+}
@@ -0,0 +1,46 @@
+package cmark
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// DocumentStats counts the elements of a document
+type DocumentStats struct {
+	Paragraphs, Headings, CodeBlocks, BlockQuotes, Lists, Links, Images int
+	// Words and Characters count the whitespace separated words and the
+	// runes of the document's text nodes
+	Words, Characters int
+}
+
+// Statistics counts the elements in the subtree of this node
+func (n Node) Statistics() DocumentStats {
+	var s DocumentStats
+	Walk(n, func(c Node, ev Event) error {
+		if ev != EventEnter {
+			return nil
+		}
+		switch typ, _ := c.Type(); typ {
+		case NodeParagraph:
+			s.Paragraphs++
+		case NodeHeading:
+			s.Headings++
+		case NodeCodeBlock:
+			s.CodeBlocks++
+		case NodeBlockQuote:
+			s.BlockQuotes++
+		case NodeList:
+			s.Lists++
+		case NodeLink:
+			s.Links++
+		case NodeImage:
+			s.Images++
+		case NodeText:
+			lit := c.Literal()
+			s.Words += len(strings.Fields(lit))
+			s.Characters += utf8.RuneCountInString(lit)
+		}
+		return nil
+	})
+	return s
+}
@@ -0,0 +1,29 @@
+//go:build !cgo
+
+package cmark
+
+import "testing"
+
+// Without cgo iterators must report EventDone straight away, or
+// everything built on Walk would loop forever
+func TestStubWalk(t *testing.T) {
+	var doc Node
+	calls := 0
+	err := Walk(doc, func(n Node, ev Event) error {
+		calls++
+		return nil
+	})
+	if err != nil || calls != 0 {
+		t.Errorf("Walk called fn %d times and returned %v", calls, err)
+	}
+	if ev := doc.Iter().Next(); ev != EventDone {
+		t.Errorf("Iter.Next = %v, want EventDone", ev)
+	}
+	doc.RenderANSI(OptDefault, 80)
+	doc.RenderMediaWiki(OptDefault)
+	doc.Statistics()
+	CollectAllLiterals(doc)
+	if _, err := AddHeadingIDs()(doc); err != nil {
+		t.Errorf("AddHeadingIDs: %v", err)
+	}
+}
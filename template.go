@@ -0,0 +1,42 @@
+package cmark
+
+import (
+	"html/template"
+	"reflect"
+	"strings"
+)
+
+// TemplateData is passed to templates by RenderToTemplate when data is
+// not a map
+type TemplateData struct {
+	// Content is the rendered html of the node
+	Content template.HTML
+	// Data is the data passed to RenderToTemplate
+	Data interface{}
+}
+
+// RenderToTemplate renders html from the node with the given options
+// and executes tmpl with it as Content
+// If data is a map with string keys its entries are merged into the
+// template's data, e.g. {{.Title}} and {{.Content}}, with Content
+// replacing an entry of that name, otherwise tmpl is executed with a
+// TemplateData holding data as Data, e.g. {{.Data.Title}}
+// Content is template.HTML so it is not escaped a second time
+func (n Node) RenderToTemplate(tmpl *template.Template, data interface{}, options Opt) (string, error) {
+	content := template.HTML(n.RenderHTML(options))
+	var tdata interface{} = TemplateData{Content: content, Data: data}
+	if v := reflect.ValueOf(data); v.Kind() == reflect.Map && v.Type().Key().Kind() == reflect.String {
+		m := make(map[string]interface{}, v.Len()+1)
+		iter := v.MapRange()
+		for iter.Next() {
+			m[iter.Key().String()] = iter.Value().Interface()
+		}
+		m["Content"] = content
+		tdata = m
+	}
+	var b strings.Builder
+	if err := tmpl.Execute(&b, tdata); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
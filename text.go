@@ -0,0 +1,77 @@
+package cmark
+
+import "strings"
+
+// ReplaceText replaces every occurrence of old with new in the text
+// nodes under this node and returns the number of replacements
+func (n Node) ReplaceText(old, new string) int {
+	if old == "" {
+		return 0
+	}
+	count := 0
+	for _, text := range collectNodes(n, NodeText) {
+		lit := text.Literal()
+		if c := strings.Count(lit, old); c > 0 {
+			text.SetLiteral(strings.ReplaceAll(lit, old, new))
+			count += c
+		}
+	}
+	return count
+}
+
+// WordCount returns the number of whitespace separated words in the
+// text and inline code under root
+func WordCount(root Node) int {
+	count := 0
+	Walk(root, func(n Node, ev Event) error {
+		if typ, _ := n.Type(); typ == NodeText || typ == NodeCode {
+			count += len(strings.Fields(n.Literal()))
+		}
+		return nil
+	})
+	return count
+}
+
+// ReadingTimeSeconds estimates how long the document under root takes
+// to read at the given reading speed, or 0 if wordsPerMinute is not
+// positive
+func ReadingTimeSeconds(root Node, wordsPerMinute int) float64 {
+	if wordsPerMinute <= 0 {
+		return 0
+	}
+	return float64(WordCount(root)) / float64(wordsPerMinute) * 60
+}
+
+// ForEachText calls fn for every text node under this node and
+// replaces the node's literal with the result
+func (n Node) ForEachText(fn func(n Node, text string) string) {
+	for _, text := range collectNodes(n, NodeText) {
+		lit := text.Literal()
+		if repl := fn(text, lit); repl != lit {
+			text.SetLiteral(repl)
+		}
+	}
+}
+
+// TextContent returns the literals of the text and inline code nodes
+// under this node concatenated, without any markup
+// Soft and hard line breaks, and the boundaries between blocks, become
+// a single space
+func (n Node) TextContent() string {
+	var b strings.Builder
+	space := false
+	Walk(n, func(c Node, ev Event) error {
+		switch typ, _ := c.Type(); {
+		case typ == NodeText || typ == NodeCode:
+			if space && b.Len() > 0 {
+				b.WriteByte(' ')
+			}
+			space = false
+			b.WriteString(c.Literal())
+		case typ == NodeSoftBreak || typ == NodeLineBreak || c.IsBlock():
+			space = true
+		}
+		return nil
+	})
+	return b.String()
+}
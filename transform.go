@@ -0,0 +1,163 @@
+package cmark
+
+import (
+	"html"
+	"strings"
+	"unicode"
+)
+
+// Transform changes a tree and returns its root, which may be a new
+// node replacing the one it was given
+type Transform func(Node) (Node, error)
+
+// ComposeTransforms returns a transform which applies each transform
+// in order to the root returned by the one before, stopping at the
+// first error
+func ComposeTransforms(transforms ...Transform) Transform {
+	return func(n Node) (Node, error) {
+		for _, t := range transforms {
+			var err error
+			if n, err = t(n); err != nil {
+				return n, err
+			}
+		}
+		return n, nil
+	}
+}
+
+// AddHeadingIDs returns a transform which starts every heading with an
+// empty anchor, <a id="..."></a>, named as by GenerateHeadingIDs
+// The anchors are custom inline nodes, so they are rendered as html
+// even by RenderHTMLSafe
+func AddHeadingIDs() Transform {
+	return func(root Node) (Node, error) {
+		headings, ids := headingIDs(root, slugify)
+		for _, h := range headings {
+			anchor := NewNode(NodeCustomInline)
+			if err := anchor.SetOnEnter(`<a id="` + html.EscapeString(ids[h]) + `"></a>`); err != nil {
+				anchor.Close()
+				return root, err
+			}
+			if err := h.PrependChild(anchor); err != nil {
+				anchor.Close()
+				return root, err
+			}
+		}
+		return root, nil
+	}
+}
+
+// SanitizeLinks returns a transform which clears the URL of every link
+// and image whose scheme is not one of allowedSchemes, e.g.
+// []string{"http", "https", "mailto"}
+// Relative URLs, which have no scheme, are kept
+func SanitizeLinks(allowedSchemes []string) Transform {
+	return func(root Node) (Node, error) {
+		root.SanitizeLinks(allowedSchemes)
+		return root, nil
+	}
+}
+
+// SanitizeLinks clears the URL of every link and image under this node
+// whose scheme is not one of allowedSchemes, e.g.
+// []string{"http", "https", "mailto"}, and returns how many were cleared
+// Schemes are compared ignoring case, relative URLs are kept
+func (n Node) SanitizeLinks(allowedSchemes []string) int {
+	count := 0
+	for _, link := range collectNodes(n, NodeLink, NodeImage) {
+		scheme := urlScheme(link.URL())
+		if scheme == "" {
+			continue
+		}
+		allowed := false
+		for _, s := range allowedSchemes {
+			if strings.EqualFold(s, scheme) {
+				allowed = true
+				break
+			}
+		}
+		if !allowed && link.SetURL("") == nil {
+			count++
+		}
+	}
+	return count
+}
+
+// urlScheme returns the lowercased scheme of url, or "" if it is
+// relative
+func urlScheme(url string) string {
+	url = strings.TrimSpace(url)
+	for i := 0; i < len(url); i++ {
+		c := url[i]
+		switch {
+		case c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z':
+		case i > 0 && (c >= '0' && c <= '9' || c == '+' || c == '-' || c == '.'):
+		case i > 0 && c == ':':
+			return strings.ToLower(url[:i])
+		default:
+			return ""
+		}
+	}
+	return ""
+}
+
+// WrapHTMLBlocks returns a transform which wraps every raw html block
+// in a custom block rendered as <div class="html-block">, so that it
+// can be styled or isolated
+func WrapHTMLBlocks() Transform {
+	return func(root Node) (Node, error) {
+		for _, block := range collectNodes(root, NodeHTMLBlock) {
+			wrapper := NewNode(NodeCustomBlock)
+			wrapper.SetOnEnter(`<div class="html-block">`)
+			wrapper.SetOnExit(`</div>`)
+			if err := block.InsertBefore(wrapper); err != nil {
+				wrapper.Close()
+				return root, err
+			}
+			if err := wrapper.AppendChild(block); err != nil {
+				return root, err
+			}
+		}
+		return root, nil
+	}
+}
+
+// NormalizeWhitespace returns a transform which collapses each run of
+// whitespace in text to a single space, removes text left empty and
+// merges adjacent text nodes
+func NormalizeWhitespace() Transform {
+	return func(root Node) (Node, error) {
+		root.ConsolidateTextNodes()
+		for _, text := range collectNodes(root, NodeText) {
+			lit := collapseSpace(text.Literal())
+			if lit == "" {
+				text.Unlink()
+				text.Close()
+				continue
+			}
+			text.SetLiteral(lit)
+		}
+		return root, nil
+	}
+}
+
+// collapseSpace replaces each run of whitespace in s with one space
+func collapseSpace(s string) string {
+	var b strings.Builder
+	space := false
+	for _, r := range s {
+		if unicode.IsSpace(r) {
+			space = true
+			continue
+		}
+		if space {
+			b.WriteByte(' ')
+			space = false
+		}
+		b.WriteRune(r)
+	}
+	if space {
+		b.WriteByte(' ')
+	}
+	return b.String()
+}
@@ -0,0 +1,151 @@
+package cmark
+
+import "errors"
+
+// ErrIndexOutOfRange is returned by ChildAt for an index which is
+// negative or not less than the number of children
+var ErrIndexOutOfRange = errors.New("Child index out of range")
+
+// RemoveChildren unlinks and Closes all children of the node
+func (n Node) RemoveChildren() {
+	for c := n.FirstChild(); c.node != nil; c = n.FirstChild() {
+		c.Unlink()
+		c.Close()
+	}
+}
+
+// WrapChildren moves all children of the node into a new node of
+// parentType, which becomes the node's only child, and returns it
+// If parentType cannot hold a child or the node cannot hold parentType
+// the tree is left unchanged and an error is returned
+func (n Node) WrapChildren(parentType NodeType) (Node, error) {
+	wrapper := NewNode(parentType)
+	if wrapper.node == nil {
+		return Node{}, n.nodeError("WrapChildren", "cannot create node of type "+parentType.String())
+	}
+	if err := n.MoveChildrenTo(wrapper); err != nil {
+		wrapper.Close()
+		return Node{}, err
+	}
+	if err := n.AppendChild(wrapper); err != nil {
+		wrapper.MoveChildrenTo(n)
+		wrapper.Close()
+		return Node{}, err
+	}
+	return wrapper, nil
+}
+
+// MoveChildrenTo unlinks all children of the node and appends them
+// to dest in order, the nodes are relinked rather than copied
+// If dest cannot hold one of the children, or is the node or one of its
+// descendants, the tree is left unchanged and an error is returned
+func (n Node) MoveChildrenTo(dest Node) error {
+	if dest.isAncestor(n) {
+		return n.nodeError("MoveChildrenTo", "cannot move children into their own subtree")
+	}
+	moved := 0
+	for c := n.FirstChild(); c.node != nil; c = n.FirstChild() {
+		if err := dest.AppendChild(c); err != nil {
+			// put back the children moved before c
+			for ; moved > 0; moved-- {
+				m := dest.LastChild()
+				m.Unlink()
+				n.PrependChild(m)
+			}
+			return err
+		}
+		moved++
+	}
+	return nil
+}
+
+// isAncestor returns true if a is n or one of its ancestors
+func (n Node) isAncestor(a Node) bool {
+	for p := n; p.node != nil; p = p.Parent() {
+		if p.node == a.node {
+			return true
+		}
+	}
+	return false
+}
+
+// SwapWith exchanges the positions of the node and other in the tree
+// An error is returned, and the tree left unchanged, if either node
+// is not in a tree, one contains the other, or either node may not be
+// a child of the other's parent
+func (n Node) SwapWith(other Node) error {
+	if n.node == other.node {
+		return nil
+	}
+	if n.Parent().node == nil || other.Parent().node == nil {
+		return n.nodeError("SwapWith", "cannot swap a node without a parent")
+	}
+	if n.isAncestor(other) || other.isAncestor(n) {
+		return n.nodeError("SwapWith", "cannot swap a node with its ancestor")
+	}
+	parent, next := other.Parent(), other.Next()
+	if err := n.Replace(other); err != nil {
+		return err
+	}
+	// other now sits where n was, move n to where other was
+	var err error
+	switch {
+	case next.node == n.node:
+		// other was directly before n
+		err = other.InsertBefore(n)
+	case next.node != nil:
+		err = next.InsertBefore(n)
+	default:
+		err = parent.AppendChild(n)
+	}
+	if err != nil {
+		other.Replace(n)
+		switch {
+		case next.node == n.node:
+			n.InsertBefore(other)
+		case next.node != nil:
+			next.InsertBefore(other)
+		default:
+			parent.AppendChild(other)
+		}
+		return err
+	}
+	return nil
+}
+
+// ChildCount returns the number of children of the node
+func (n Node) ChildCount() int {
+	count := 0
+	for c := n.FirstChild(); c.node != nil; c = c.Next() {
+		count++
+	}
+	return count
+}
+
+// ChildAt returns the child at index, counting from 0
+func (n Node) ChildAt(index int) (Node, error) {
+	if index < 0 {
+		return Node{}, ErrIndexOutOfRange
+	}
+	c := n.FirstChild()
+	for ; index > 0 && c.node != nil; index-- {
+		c = c.Next()
+	}
+	if c.node == nil {
+		return Node{}, ErrIndexOutOfRange
+	}
+	return c, nil
+}
+
+// IndexInParent returns the position of the node among its parent's
+// children, counting from 0, it is the inverse of ChildAt
+func (n Node) IndexInParent() (int, error) {
+	if n.Parent().node == nil {
+		return 0, n.nodeError("IndexInParent", "node has no parent")
+	}
+	index := 0
+	for s := n.Previous(); s.node != nil; s = s.Previous() {
+		index++
+	}
+	return index, nil
+}
@@ -0,0 +1,68 @@
+package cmark
+
+import "fmt"
+
+// canContain mirrors cmark's rules for which node types may be
+// children of which
+func canContain(parent, child NodeType) bool {
+	if child == NodeDocument {
+		return false
+	}
+	if can, ok := extensionCanContain(parent, child); ok {
+		return can
+	}
+	isBlock, isInline := isBlockType(child), isInlineType(child)
+	switch parent {
+	case NodeDocument, NodeBlockQuote, NodeItem:
+		return isBlock && child != NodeItem
+	case NodeList:
+		return child == NodeItem
+	case NodeCustomBlock:
+		return true
+	case NodeParagraph, NodeHeading, NodeEmph, NodeStrong, NodeLink,
+		NodeImage, NodeCustomInline:
+		return isInline
+	}
+	return false
+}
+
+// CanContain returns true if a node of type childType may be appended
+// to this node, following the same rules as cmark does for the core
+// node types, and with the cmark_gfm tag for the types of the core
+// extensions
+//
+// AppendChild, PrependChild, InsertBefore and InsertAfter already fail
+// for invalid children, CanContain allows checking beforehand
+func (n Node) CanContain(childType NodeType) bool {
+	typ, err := n.Type()
+	return err == nil && canContain(typ, childType)
+}
+
+// Validate checks that the subtree of this node is a valid CommonMark
+// tree: every child type is allowed in its parent, list items are only
+// found in lists and headings have a level from 1 to 6
+// The error names the first offending node and its line
+func (n Node) Validate() error {
+	return Walk(n, func(c Node, ev Event) error {
+		if ev != EventEnter {
+			return nil
+		}
+		typ, err := c.Type()
+		if err != nil {
+			return fmt.Errorf("Node at line %d: %v", c.StartLine(), err)
+		}
+		if typ == NodeHeading {
+			if level, _ := c.HeadingLevel(); level < 1 || level > 6 {
+				return fmt.Errorf("Heading at line %d has invalid level %d", c.StartLine(), level)
+			}
+		}
+		if c.node == n.node {
+			return nil
+		}
+		ptyp, _ := c.Parent().Type()
+		if !canContain(ptyp, typ) {
+			return fmt.Errorf("%v at line %d cannot be a child of %v", typ, c.StartLine(), ptyp)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,88 @@
+package cmark
+
+import "context"
+
+// Walk calls fn for every event while iterating the tree under root,
+// stopping at the first error which is returned
+//
+// Leaf nodes only receive EventEnter, container nodes receive
+// EventEnter before their children and EventExit after
+func Walk(root Node, fn func(n Node, ev Event) error) error {
+	iter := root.Iter()
+	defer iter.Close()
+	for ev := iter.Next(); ev != EventDone; ev = iter.Next() {
+		if err := fn(iter.Node(), ev); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// IterEvent is an event and the node it occurred on
+type IterEvent struct {
+	Node  Node
+	Event Event
+}
+
+// Events iterates the tree under this node in a new goroutine and
+// sends each event on the returned channel, which is closed when the
+// iteration is done or ctx is cancelled
+//
+// The tree must not be modified or closed until the channel is closed
+func (n Node) Events(ctx context.Context) <-chan IterEvent {
+	ch := make(chan IterEvent)
+	go func() {
+		defer close(ch)
+		iter := n.Iter()
+		defer iter.Close()
+		for ev := iter.Next(); ev != EventDone; ev = iter.Next() {
+			select {
+			case ch <- IterEvent{Node: iter.Node(), Event: ev}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return ch
+}
+
+// ToSlice returns the remaining events of the iterator and closes it
+func (i Iter) ToSlice() []IterEvent {
+	defer i.Close()
+	// each child of the root is entered and usually exited
+	events := make([]IterEvent, 0, 2*i.Root().ChildCount()+2)
+	for ev := i.Next(); ev != EventDone; ev = i.Next() {
+		events = append(events, IterEvent{Node: i.Node(), Event: ev})
+	}
+	return events
+}
+
+// FilteredIter is an iterator which only stops at some events,
+// its other methods are those of the wrapped Iter
+type FilteredIter struct {
+	Iter
+	events []Event
+}
+
+// Filter returns an iterator whose Next only returns the given events,
+// or EventDone, e.g. i.Filter(EventEnter) visits each node once
+// Closing either iterator closes both
+func (i Iter) Filter(events ...Event) FilteredIter {
+	return FilteredIter{Iter: i, events: events}
+}
+
+// Next advances the iterator until one of the filter's events or
+// EventDone occurs, and returns it
+func (f FilteredIter) Next() Event {
+	for {
+		ev := f.Iter.Next()
+		if ev == EventDone || ev == EventNone {
+			return ev
+		}
+		for _, e := range f.events {
+			if ev == e {
+				return ev
+			}
+		}
+	}
+}